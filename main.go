@@ -1,43 +1,451 @@
+// Command gomusic renders, plays and inspects compositions.
+//
+//	gomusic render [-o out.wav] [-rate 44100] [-format int16] [-duration 10s] [-dither none] [-softclip] [-removedc] [-endian big] <song>
+//	gomusic play [-rate 44100] [-duration 10s] <song>
+//	gomusic live [-rate 44100] [-fade 500ms] <patch.json>
+//	gomusic process [-rate 44100] [-format int16] [-endian big] <patch.json>
+//	gomusic inspect [-spectrogram out.png] [-waveform out.png] <song|file.wav|file.aiff>
+//
+// Songs are the compositions registered in songs.go (ex: "demo") or patch files ending with ".json" (see package patch).
+// Live mode plays a patch file and reloads it whenever it's saved, crossfading to the new version.
+// Process mode applies a patch file (its "input" nodes) to raw mono PCM read from stdin and writes the result to stdout,
+// in the same format (ex: "sox in.wav -t s16 -c 1 - | gomusic process -endian little fx.json | sox -t s16 -r 44100 -c 1 - out.wav").
+// Rendering to a file ending with ".aiff" writes an AIFF file (AIFF-C for float formats),
+// ".flac" writes a FLAC file (float formats are written as 24-bit frames),
+// files ending with ".ogg" (Vorbis) or ".opus" are compressed by an external encoder (oggenc, opusenc or ffmpeg),
+// and rendering to any other file (or to "-", stdout) writes raw PCM (big-endian unless -endian is little),
+// which can be played with ffplay (ex: "ffplay -f s16be -ar 44100 out.pcm")
+// or converted with sox (ex: "gomusic render -o - -format float32 -endian little demo | sox -t f32 -r 44100 -c 1 - out.mp3").
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/ejuju/poc-go-music/pkg/dsp"
-	"github.com/ejuju/poc-go-music/pkg/music"
+	"github.com/ejuju/poc-go-music/pkg/patch"
+	"github.com/ejuju/poc-go-music/pkg/playback"
 )
 
+const usage = `usage:
+  gomusic render [-o out.wav] [-rate 44100] [-format int16] [-duration 10s] [-dither none] [-softclip] [-removedc] [-endian big] <song>
+  gomusic play [-rate 44100] [-duration 10s] <song>
+  gomusic live [-rate 44100] [-fade 500ms] <patch.json>
+  gomusic process [-rate 44100] [-format int16] [-endian big] <patch.json>
+  gomusic inspect [-spectrogram out.png] [-waveform out.png] <song|file.wav|file.aiff>
+`
+
 func main() {
-	bpm := music.BPM(127)
-
-	chord1 := dsp.Combine(
-		dsp.Sine(music.C4),
-		dsp.Sine(music.E4),
-		dsp.Sine(music.G4),
-	)
-	chord2 := dsp.Combine(
-		dsp.Sine(music.A4),
-		dsp.Sine(music.C4),
-		dsp.Sine(music.E4),
-	)
-	chord3 := dsp.Combine(
-		dsp.Sine(music.E4),
-		dsp.Sine(music.B4),
-		dsp.Sine(music.G4),
-	)
-	chord4 := dsp.Combine(
-		dsp.Sine(music.D4),
-		dsp.Sine(music.A4),
-		dsp.Sine(music.Gb4),
-	)
-
-	s := dsp.Sequence(
-		dsp.F(bpm.T(4), dsp.Amplify(chord1, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
-		dsp.F(bpm.T(4), dsp.Amplify(chord2, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
-		dsp.F(bpm.T(4), dsp.Amplify(chord3, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
-		dsp.F(bpm.T(4), dsp.Amplify(chord4, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
-	)
-
-	frames := dsp.Sample(s, 44100, 0, bpm.T(16))
-	os.Stdout.Write(dsp.EncodePCM(frames))
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "render":
+		err = render(args)
+	case "play":
+		err = play(args)
+	case "live":
+		err = live(args)
+	case "process":
+		err = process(args)
+	case "inspect":
+		err = inspect(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gomusic:", err)
+		os.Exit(1)
+	}
+}
+
+var formats = map[string]dsp.SampleFormat{
+	"float64": dsp.Float64,
+	"float32": dsp.Float32,
+	"int16":   dsp.Int16,
+	"int24":   dsp.Int24,
+	"int32":   dsp.Int32,
+	"uint8":   dsp.Uint8,
+}
+
+func render(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "out.wav", "output file (WAV, AIFF, FLAC, Ogg Vorbis or Opus if it ends with .wav, .aiff, .flac, .ogg or .opus, raw PCM otherwise, - for stdout)")
+	rate := fs.Int("rate", 44100, "sample rate (in Hertz)")
+	format := fs.String("format", "int16", "sample format (float64, float32, int16, int24, int32 or uint8)")
+	duration := fs.Duration("duration", 0, "duration to render (defaults to the duration of the song)")
+	dither := fs.String("dither", "none", "dither added to integer formats (none, tpdf or shaped)")
+	softClip := fs.Bool("softclip", false, "round off peaks instead of letting them clip")
+	removeDC := fs.Bool("removedc", false, "remove the DC offset before encoding")
+	endian := fs.String("endian", "big", "byte order of raw PCM (big or little)")
+	s, err := loadSong(parseArgs(fs, args), *rate, *duration)
+	if err != nil {
+		return err
+	}
+	opts := dsp.EncodeOptions{RemoveDC: *removeDC}
+	if opts.Format, err = parseFormat(*format); err != nil {
+		return err
+	}
+	if opts.Dither, err = parseDither(*dither); err != nil {
+		return err
+	}
+	if opts.LittleEndian, err = parseEndian(*endian); err != nil {
+		return err
+	}
+	if *softClip {
+		s.Signal = dsp.SoftClip(s.Signal, 0.8)
+	}
+	var clips clipReport
+	s.Signal = dsp.DetectClips(s.Signal, clips.add)
+
+	if *out == "-" {
+		err = dsp.Stream(os.Stdout, s, *rate, 0, s.Duration, opts)
+	} else {
+		err = writeFile(*out, func(f *os.File) error {
+			switch strings.ToLower(filepath.Ext(*out)) {
+			case ".wav":
+				return dsp.StreamWAV(f, s, *rate, 0, s.Duration, opts)
+			case ".aif", ".aiff":
+				_, err := f.Write(dsp.EncodeAIFF(dsp.Sample(s, *rate, 0, s.Duration), *rate, 1, opts))
+				return err
+			case ".flac":
+				_, err := f.Write(dsp.EncodeFLAC(dsp.Sample(s, *rate, 0, s.Duration), *rate, 1, opts))
+				return err
+			case ".ogg", ".opus":
+				opts.Format = dsp.Float32 // The encoder converts to its own format
+				return compress(f, filepath.Ext(*out), dsp.EncodeWAV(dsp.Sample(s, *rate, 0, s.Duration), *rate, 1, opts))
+			}
+			return dsp.Stream(f, s, *rate, 0, s.Duration, opts)
+		})
+	}
+	clips.print(os.Stderr)
+	return err
+}
+
+// Clipped frames found while rendering.
+type clipReport struct {
+	count  int
+	peak   float64
+	places []time.Duration // Where each run of clipped frames starts
+	last   time.Duration
+}
+
+func (r *clipReport) add(x time.Duration, y float64) {
+	if r.count == 0 || x-r.last > 10*time.Millisecond {
+		r.places = append(r.places, x)
+	}
+	r.count++
+	r.peak = max(r.peak, y, -y)
+	r.last = x
+}
+
+func (r *clipReport) print(w io.Writer) {
+	if r.count == 0 {
+		return
+	}
+	places := make([]string, 0, 10)
+	for _, x := range r.places[:min(len(r.places), cap(places))] {
+		places = append(places, x.Round(time.Millisecond).String())
+	}
+	if len(r.places) > len(places) {
+		places = append(places, "...")
+	}
+	fmt.Fprintf(w, "warning: %d frames clipped (peak %+.1f dBFS) at %s (use -softclip or lower the volume)\n",
+		r.count, 20*math.Log10(r.peak), strings.Join(places, ", "))
+}
+
+func play(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	rate := fs.Int("rate", 44100, "sample rate (in Hertz)")
+	duration := fs.Duration("duration", 0, "duration to play (defaults to the duration of the song)")
+	s, err := loadSong(parseArgs(fs, args), *rate, *duration)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = playback.Play(ctx, s, s.Duration, playback.Options{Rate: *rate})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// How long live sessions last (unless interrupted).
+const liveDuration = 24 * time.Hour
+
+func live(args []string) error {
+	fs := flag.NewFlagSet("live", flag.ExitOnError)
+	rate := fs.Int("rate", 44100, "sample rate (in Hertz)")
+	fade := fs.Duration("fade", 500*time.Millisecond, "crossfade duration when the patch is reloaded")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errors.New("expected a patch file")
+	}
+	path := args[0]
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	s := &dsp.HotSwap{Fade: *fade}
+	go patch.Watch(ctx, path, *rate, s, func(err error) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gomusic:", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%s: loaded %s\n", time.Now().Format(time.TimeOnly), path)
+	})
+	err := playback.Play(ctx, s, liveDuration, playback.Options{Rate: *rate})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func process(args []string) error {
+	fs := flag.NewFlagSet("process", flag.ExitOnError)
+	rate := fs.Int("rate", 44100, "sample rate (in Hertz)")
+	format := fs.String("format", "int16", "sample format (float64, float32, int16, int24, int32 or uint8)")
+	endian := fs.String("endian", "big", "byte order (big or little)")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errors.New("expected a patch file")
+	}
+	var opts dsp.EncodeOptions
+	var err error
+	if opts.Format, err = parseFormat(*format); err != nil {
+		return err
+	}
+	if opts.LittleEndian, err = parseEndian(*endian); err != nil {
+		return err
+	}
+	p, err := patch.Load(args[0])
+	if err != nil {
+		return err
+	}
+	in := &dsp.PCMReader{Reader: os.Stdin, Rate: *rate, Options: opts}
+	s, err := p.BuildWithInput(*rate, in)
+	if err != nil {
+		return err
+	}
+
+	// Processes one chunk at a time, until the end of the input.
+	out := bufio.NewWriter(os.Stdout)
+	frames := make([]float64, 0, dsp.StreamChunkSize)
+	e := dsp.NewPCMEncoder(*rate, opts)
+	for i := int64(0); ; i++ {
+		x := dsp.FrameTime(i, *rate)
+		in.At(x) // Reads the input up to the frame, even if the patch doesn't use it
+		if in.Err() != nil && i >= dsp.FrameCount(in.Duration(), *rate) {
+			break
+		}
+		frames = append(frames, s.At(x))
+		if len(frames) == cap(frames) {
+			if _, err := out.Write(e.Encode(frames)); err != nil {
+				return err
+			}
+			frames = frames[:0]
+		}
+	}
+	if _, err := out.Write(e.Encode(frames)); err != nil {
+		return err
+	}
+	if err := in.Err(); err != io.EOF {
+		return fmt.Errorf("read input: %w", err)
+	}
+	return out.Flush()
+}
+
+func inspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	rate := fs.Int("rate", 44100, "sample rate used to render songs (in Hertz)")
+	spectrogram := fs.String("spectrogram", "", "write a spectrogram to the given PNG file")
+	waveform := fs.String("waveform", "", "write the waveform to the given PNG file")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errors.New("expected a song name or a WAV or AIFF file")
+	}
+
+	var b dsp.Buffer
+	var err error
+	switch name := args[0]; strings.ToLower(filepath.Ext(name)) {
+	case ".wav":
+		if b, err = dsp.LoadWAV(name); err != nil {
+			return err
+		}
+	case ".aif", ".aiff", ".aifc":
+		if b, err = dsp.LoadAIFF(name); err != nil {
+			return err
+		}
+	default:
+		s, err := loadSong(args, *rate, 0)
+		if err != nil {
+			return err
+		}
+		b = dsp.Buffer{Frames: dsp.Sample(s, *rate, 0, s.Duration), Rate: *rate}
+	}
+
+	l := dsp.Measure(b.Frames, b.Rate, 1)
+	fmt.Printf("duration:  %s\n", b.Duration())
+	fmt.Printf("rate:      %d Hz\n", b.Rate)
+	fmt.Printf("frames:    %d\n", len(b.Frames))
+	fmt.Printf("peak:      %.1f dBFS\n", l.Peak)
+	fmt.Printf("true peak: %.1f dBTP\n", l.TruePeak)
+	fmt.Printf("rms:       %.1f dBFS\n", l.RMS)
+	fmt.Printf("loudness:  %.1f LUFS\n", l.LUFS)
+
+	if *spectrogram != "" {
+		err := writeFile(*spectrogram, func(f *os.File) error {
+			return dsp.SpectrogramPNG(f, b.Frames, b.Rate, dsp.SpectrogramOptions{})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if *waveform != "" {
+		return writeFile(*waveform, func(f *os.File) error {
+			return dsp.WaveformPNG(f, b.Frames, 1200, 300, dsp.WaveformOptions{})
+		})
+	}
+	return nil
+}
+
+// Creates the file and writes to it, the file is removed if writing fails.
+func writeFile(name string, write func(f *os.File) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	return f.Close()
+}
+
+// Writes the WAV file compressed as Ogg Vorbis (".ogg") or Ogg Opus (".opus"), with the first encoder found in $PATH.
+// The standard library has no lossy audio encoder, and they're too large to be written here.
+func compress(w io.Writer, ext string, wav []byte) error {
+	var cmd *exec.Cmd
+	switch {
+	case ext == ".ogg" && lookPath("oggenc"):
+		cmd = exec.Command("oggenc", "--quiet", "-o", "-", "-")
+	case ext == ".opus" && lookPath("opusenc"):
+		cmd = exec.Command("opusenc", "--quiet", "-", "-")
+	case lookPath("ffmpeg"):
+		codec := map[string]string{".ogg": "libvorbis", ".opus": "libopus"}[ext]
+		cmd = exec.Command("ffmpeg", "-loglevel", "error", "-f", "wav", "-i", "-", "-c:a", codec, "-f", "ogg", "-")
+	default:
+		if ext == ".ogg" {
+			return errors.New("no Vorbis encoder found (install oggenc or ffmpeg)")
+		}
+		return errors.New("no Opus encoder found (install opusenc or ffmpeg)")
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(wav), w, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func lookPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// Parses the flags, which can come before or after the other arguments (ex: "render demo -o demo.wav"),
+// and returns the other arguments.
+func parseArgs(fs *flag.FlagSet, args []string) (rest []string) {
+	fs.Parse(args)
+	for fs.NArg() > 0 {
+		rest = append(rest, fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+	return rest
+}
+
+// Returns the song named by the (only) argument, lasting the given duration (if not 0).
+// Arguments ending with ".json" are loaded as patches (rendered at the given rate).
+func loadSong(args []string, rate int, d time.Duration) (dsp.FiniteSignal, error) {
+	if len(args) != 1 {
+		return dsp.FiniteSignal{}, errors.New("expected a song name")
+	}
+	if strings.HasSuffix(args[0], ".json") {
+		p, err := patch.Load(args[0])
+		if err != nil {
+			return dsp.FiniteSignal{}, err
+		}
+		if d > 0 {
+			p.Duration = patch.Duration(d)
+		}
+		s, err := p.Build(rate)
+		if err != nil {
+			return dsp.FiniteSignal{}, fmt.Errorf("build %s: %w", args[0], err)
+		}
+		return s, nil
+	}
+	song, ok := songs[args[0]]
+	if !ok {
+		names := make([]string, 0, len(songs))
+		for name := range songs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return dsp.FiniteSignal{}, fmt.Errorf("unknown song %q (available: %s)", args[0], strings.Join(names, ", "))
+	}
+	s := song()
+	if d > 0 {
+		s.Duration = d
+	}
+	return s, nil
+}
+
+var dithers = map[string]dsp.Dither{
+	"none":   dsp.NoDither,
+	"tpdf":   dsp.TPDF,
+	"shaped": dsp.NoiseShaped,
+}
+
+func parseDither(name string) (dsp.Dither, error) {
+	d, ok := dithers[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown dither %q", name)
+	}
+	return d, nil
+}
+
+func parseFormat(name string) (dsp.SampleFormat, error) {
+	f, ok := formats[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown sample format %q", name)
+	}
+	return f, nil
+}
+
+// Returns whether the byte order is little-endian.
+func parseEndian(name string) (little bool, err error) {
+	switch name {
+	case "big":
+		return false, nil
+	case "little":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown byte order %q (expected big or little)", name)
 }
@@ -0,0 +1,145 @@
+// Package drums synthesizes drum sounds (kick, snare, hi-hats and clap).
+//
+// Each drum is described by a struct (see the presets) whose Signal method
+// returns a single hit as a finite signal (starting at 0).
+// Kit bundles them as an instrument, so drum patterns can be played by a step sequencer:
+//
+//	music.StepSequencer{
+//		BPM:        127,
+//		Steps:      music.PatternSteps(music.Euclid(4, 16, 0), drums.KickNote),
+//		Instrument: drums.Kit(44100),
+//	}
+package drums
+
+import (
+	"math"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+// Notes of each drum in Kit (following the General MIDI percussion map).
+const (
+	KickNote        = music.C2
+	SnareNote       = music.D2
+	ClapNote        = music.Eb2
+	ClosedHiHatNote = music.Gb2
+	OpenHiHatNote   = music.Bb2
+)
+
+// Instrument playing the preset drums (see the note constants), with an amplitude proportional to the velocity.
+// Other notes are silent.
+func Kit(rate int) music.Instrument {
+	return func(n music.Note, velocity float64) dsp.Signal {
+		var hit dsp.FiniteSignal
+		switch n {
+		case KickNote:
+			hit = DefaultKick.Signal(rate)
+		case SnareNote:
+			hit = DefaultSnare.Signal(rate)
+		case ClapNote:
+			hit = DefaultClap.Signal(rate)
+		case ClosedHiHatNote:
+			hit = ClosedHiHat.Signal(rate)
+		case OpenHiHatNote:
+			hit = OpenHiHat.Signal(rate)
+		default:
+			return dsp.Constant(0)
+		}
+		return dsp.Amplify(hit, dsp.Constant(velocity))
+	}
+}
+
+// Sine whose pitch drops quickly (from Start to End), with a click at the start.
+type Kick struct {
+	Start, End float64       // Pitch (in Hertz)
+	Sweep      time.Duration // How fast the pitch drops (time constant)
+	Decay      time.Duration
+	Click      float64 // Level of the click (from 0 to 1)
+}
+
+var DefaultKick = Kick{Start: 160, End: 50, Sweep: 40 * time.Millisecond, Decay: 500 * time.Millisecond, Click: 0.3}
+
+func (k Kick) Signal(rate int) dsp.FiniteSignal {
+	tau := k.Sweep.Seconds()
+	click := dsp.HighPass(dsp.Noise(1), rate, dsp.Constant(2000), dsp.Constant(0.7))
+	return dsp.F(k.Decay, dsp.SignalFunc(func(x time.Duration) (y float64) {
+		t := x.Seconds()
+		// The phase is the integral of the (exponentially falling) frequency.
+		phase := k.End*t + (k.Start-k.End)*tau*(1-math.Exp(-t/tau))
+		y = math.Sin(2*math.Pi*phase) * decay(x, k.Decay)
+		if x < 3*time.Millisecond {
+			y += k.Click * click.At(x) * (1 - t/0.003)
+		}
+		return y / (1 + k.Click)
+	}))
+}
+
+// Short tone mixed with a burst of (high-passed) noise.
+type Snare struct {
+	Tone  float64 // Pitch of the tone (in Hertz)
+	Decay time.Duration
+	Noise float64 // Proportion of noise (from 0 to 1)
+}
+
+var DefaultSnare = Snare{Tone: 180, Decay: 200 * time.Millisecond, Noise: 0.7}
+
+func (s Snare) Signal(rate int) dsp.FiniteSignal {
+	tone := dsp.Sine(dsp.Constant(s.Tone))
+	noise := dsp.HighPass(dsp.Noise(2), rate, dsp.Constant(1500), dsp.Constant(0.7))
+	return dsp.F(s.Decay, dsp.SignalFunc(func(x time.Duration) (y float64) {
+		return (1-s.Noise)*tone.At(x)*decay(x, s.Decay/2) + s.Noise*noise.At(x)*decay(x, s.Decay)
+	}))
+}
+
+// High-passed mix of noise and detuned square waves (like the TR-808).
+type HiHat struct {
+	Cutoff float64 // High-pass cutoff frequency (in Hertz)
+	Decay  time.Duration
+}
+
+var (
+	ClosedHiHat = HiHat{Cutoff: 7000, Decay: 60 * time.Millisecond}
+	OpenHiHat   = HiHat{Cutoff: 7000, Decay: 400 * time.Millisecond}
+)
+
+func (h HiHat) Signal(rate int) dsp.FiniteSignal {
+	metal := make([]dsp.Signal, 0, 7)
+	for _, f := range []float64{205.3, 304.4, 369.6, 522.7, 540, 800} {
+		metal = append(metal, dsp.Square(dsp.Constant(f)))
+	}
+	metal = append(metal, dsp.Noise(3))
+	hat := dsp.HighPass(dsp.Combine(metal...), rate, dsp.Constant(h.Cutoff), dsp.Constant(0.7))
+	return dsp.F(h.Decay, dsp.SignalFunc(func(x time.Duration) (y float64) {
+		return 2 * hat.At(x) * decay(x, h.Decay)
+	}))
+}
+
+// Several quick bursts of band-passed noise followed by a longer tail.
+type Clap struct {
+	Bursts  int           // Number of bursts before the tail
+	Spacing time.Duration // Time between bursts
+	Decay   time.Duration // Length of the tail
+}
+
+var DefaultClap = Clap{Bursts: 3, Spacing: 10 * time.Millisecond, Decay: 250 * time.Millisecond}
+
+func (c Clap) Signal(rate int) dsp.FiniteSignal {
+	noise := dsp.BandPass(dsp.Noise(4), rate, dsp.Constant(1200), dsp.Constant(1.5))
+	tail := time.Duration(c.Bursts) * c.Spacing
+	return dsp.F(tail+c.Decay, dsp.SignalFunc(func(x time.Duration) (y float64) {
+		if x < tail {
+			return 2 * noise.At(x) * decay(x%c.Spacing, c.Spacing)
+		}
+		return 2 * noise.At(x) * decay(x-tail, c.Decay)
+	}))
+}
+
+// Exponential decay reaching -60dB at x = d.
+func decay(x, d time.Duration) float64 {
+	if x < 0 || x >= d {
+		return 0
+	}
+	return math.Pow(10, -3*float64(x)/float64(d))
+}
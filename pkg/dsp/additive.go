@@ -0,0 +1,47 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Sine component of an additive synthesis signal.
+type Partial struct {
+	Ratio  float64 // Frequency relative to the fundamental
+	Amp    float64 // Relative amplitude
+	Detune float64 // Detuning (in cents)
+}
+
+var (
+	// Drawbar organ (16', 8', 5 1/3', 4', 2 2/3', 2').
+	OrganPartials = []Partial{{0.5, 0.6, 0}, {1, 1, 0}, {1.5, 0.5, 0}, {2, 0.6, 0}, {3, 0.3, 0}, {4, 0.3, 0}}
+
+	// Inharmonic bell (inspired by Jean-Claude Risset's bell).
+	BellPartials = []Partial{
+		{0.56, 1, 0}, {0.56, 0.67, 1}, {0.92, 1, 0}, {0.92, 1.8, 1.7}, {1.19, 2.67, 0},
+		{1.7, 1.67, 0}, {2, 1.46, 0}, {2.74, 1.33, 0}, {3, 1.33, 0}, {3.76, 1, 0}, {4.07, 1.33, 0},
+	}
+)
+
+// Sums sine partials of the fundamental frequency (normalized so that the output stays between -1 and 1).
+// The phase of the fundamental is computed once and shared by all partials.
+//
+// Ex: dsp.Additive(music.A4, dsp.OrganPartials)
+func Additive(fundamental Signal, partials []Partial) Signal {
+	ratios := make([]float64, len(partials))
+	total := 0.0
+	for i, p := range partials {
+		ratios[i] = p.Ratio * math.Pow(2, p.Detune/1200)
+		total += math.Abs(p.Amp)
+	}
+	return SignalFunc(func(x time.Duration) (y float64) {
+		if total == 0 {
+			return 0
+		}
+		phase := x.Seconds() * fundamental.At(x)
+		for i, p := range partials {
+			y += p.Amp * math.Sin(2*math.Pi*frac(phase*ratios[i]))
+		}
+		return y / total
+	})
+}
@@ -0,0 +1,184 @@
+package dsp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+)
+
+// Encodes frames as an AIFF file (big-endian PCM), or as an AIFF-C file for float formats.
+// When channels > 1, frames must be interleaved (L, R, L, R, ...).
+func EncodeAIFF(frames []float64, rate, channels int, opts EncodeOptions) (b []byte) {
+	size := opts.Format.Size()
+	dataSize := len(frames) * size
+
+	// Float formats are only supported by AIFF-C, which adds a version chunk and a compression type.
+	form, commSize, fverSize := "AIFF", 18, 0
+	compression := map[SampleFormat]string{Float32: "fl32", Float64: "fl64"}[opts.Format]
+	if compression != "" {
+		form, commSize, fverSize = "AIFC", 18+4+2, 12 // Compression type, followed by an empty name (with padding)
+	}
+
+	b = make([]byte, 0, 12+fverSize+8+commSize+16+dataSize+1)
+	b = append(b, "FORM"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(4+fverSize+8+commSize+16+dataSize+dataSize%2))
+	b = append(b, form...)
+
+	if fverSize > 0 {
+		b = append(b, "FVER"...)
+		b = binary.BigEndian.AppendUint32(b, 4)
+		b = binary.BigEndian.AppendUint32(b, 0xA2805140) // AIFF-C version 1
+	}
+
+	b = append(b, "COMM"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(commSize))
+	b = binary.BigEndian.AppendUint16(b, uint16(channels))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(frames)/channels))
+	b = binary.BigEndian.AppendUint16(b, uint16(size*8))
+	b = appendExtended(b, float64(rate))
+	if compression != "" {
+		b = append(b, compression...)
+		b = append(b, 0, 0)
+	}
+
+	b = append(b, "SSND"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(8+dataSize))
+	b = binary.BigEndian.AppendUint32(b, 0) // Offset
+	b = binary.BigEndian.AppendUint32(b, 0) // Block size
+	e := newEncoder(opts, binary.BigEndian, rate)
+	for _, pulse := range frames {
+		b = e.append(b, pulse)
+		if opts.Format == Uint8 {
+			b[len(b)-1] ^= 0x80 // 8-bit AIFF frames are signed
+		}
+	}
+	if dataSize%2 == 1 {
+		b = append(b, 0) // Chunks are padded to an even size.
+	}
+	return b
+}
+
+// Reads an AIFF (or AIFF-C) file into a buffer (channels are mixed down to mono).
+func LoadAIFF(path string) (b Buffer, err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	frames, rate, channels, err := DecodeAIFF(raw)
+	if err != nil {
+		return b, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return mixDown(frames, rate, channels), nil
+}
+
+// Decodes an AIFF (or AIFF-C) file into frames (interleaved when channels > 1), along with its sample rate.
+func DecodeAIFF(b []byte) (frames []float64, rate, channels int, err error) {
+	if len(b) < 12 || string(b[:4]) != "FORM" || (string(b[8:12]) != "AIFF" && string(b[8:12]) != "AIFC") {
+		return nil, 0, 0, errors.New("not an AIFF file")
+	}
+	var format SampleFormat
+	order := binary.ByteOrder(binary.BigEndian)
+	hasFormat := false
+	for b = b[12:]; len(b) >= 8; {
+		id, size := string(b[:4]), int(binary.BigEndian.Uint32(b[4:8]))
+		b = b[8:]
+		if size > len(b) {
+			size = len(b)
+		}
+		chunk := b[:size]
+		b = b[min(size+size%2, len(b)):] // Chunks are padded to an even size.
+
+		switch id {
+		case "COMM":
+			if size < 18 {
+				return nil, 0, 0, errors.New("invalid COMM chunk")
+			}
+			channels = int(binary.BigEndian.Uint16(chunk[0:2]))
+			bits := int(binary.BigEndian.Uint16(chunk[6:8]))
+			rate = int(math.Round(extended(chunk[8:18])))
+			compression := "NONE"
+			if size >= 22 {
+				compression = string(chunk[18:22])
+			}
+			if format, order, err = aiffFormat(compression, bits); err != nil {
+				return nil, 0, 0, err
+			}
+			if channels < 1 {
+				return nil, 0, 0, errors.New("invalid number of channels")
+			}
+			hasFormat = true
+		case "SSND":
+			if !hasFormat {
+				return nil, 0, 0, errors.New("missing COMM chunk before SSND chunk")
+			}
+			if size < 8 {
+				return nil, 0, 0, errors.New("invalid SSND chunk")
+			}
+			chunk = chunk[min(8+int(binary.BigEndian.Uint32(chunk[0:4])), len(chunk)):]
+			size := format.Size()
+			frames = make([]float64, len(chunk)/size/channels*channels)
+			for i := range frames {
+				if format == Uint8 {
+					frames[i] = float64(int8(chunk[i])) / 127
+					continue
+				}
+				frames[i] = decodeFrame(chunk[i*size:], order, format)
+			}
+			return frames, rate, channels, nil
+		}
+	}
+	return nil, 0, 0, errors.New("missing SSND chunk")
+}
+
+// Returns the format of the frames for the AIFF-C compression type ("NONE" for AIFF files).
+func aiffFormat(compression string, bits int) (SampleFormat, binary.ByteOrder, error) {
+	switch {
+	case (compression == "NONE" || compression == "sowt") && bits == 8:
+		return Uint8, binary.BigEndian, nil
+	case compression == "NONE" && bits == 16:
+		return Int16, binary.BigEndian, nil
+	case compression == "NONE" && bits == 24:
+		return Int24, binary.BigEndian, nil
+	case compression == "NONE" && bits == 32:
+		return Int32, binary.BigEndian, nil
+	case compression == "sowt" && bits == 16: // Little-endian PCM
+		return Int16, binary.LittleEndian, nil
+	case compression == "sowt" && bits == 24:
+		return Int24, binary.LittleEndian, nil
+	case compression == "sowt" && bits == 32:
+		return Int32, binary.LittleEndian, nil
+	case compression == "fl32" || compression == "FL32":
+		return Float32, binary.BigEndian, nil
+	case compression == "fl64" || compression == "FL64":
+		return Float64, binary.BigEndian, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported AIFF format (%q, %d bits)", compression, bits)
+}
+
+// Appends a positive number as an 80-bit IEEE 754 extended precision float (used for the sample rate).
+func appendExtended(b []byte, v float64) []byte {
+	if v <= 0 {
+		return append(b, make([]byte, 10)...)
+	}
+	frac, exp := math.Frexp(v) // v = frac × 2^exp, with frac in [0.5, 1)
+	b = binary.BigEndian.AppendUint16(b, uint16(exp-1+16383))
+	return binary.BigEndian.AppendUint64(b, uint64(frac*(1<<64)))
+}
+
+// Decodes an 80-bit IEEE 754 extended precision float.
+func extended(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]))
+	sign := 1.0
+	if exp&0x8000 != 0 {
+		sign, exp = -1, exp&0x7FFF
+	}
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+	if mantissa == 0 {
+		return 0
+	}
+	shift := bits.LeadingZeros64(mantissa) // Normalized numbers have the top bit set
+	return sign * math.Ldexp(float64(mantissa<<shift), exp-16383-63-shift)
+}
@@ -0,0 +1,111 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Block-based processing: frames are computed a block at a time instead of one by one,
+// which avoids most of the per-frame overhead of nested signals (interface calls, recomputed constants, etc.).
+type Processor interface {
+	// Fills buf with consecutive frames, starting at the frame with the given index.
+	Process(buf []float64, start int64)
+}
+
+type ProcessorFunc func(buf []float64, start int64)
+
+func (f ProcessorFunc) Process(buf []float64, start int64) { f(buf, start) }
+
+// Number of frames processed at once by Render.
+const BlockSize = 512
+
+// Adapts a signal to the block API.
+func Block(s Signal, rate int) Processor {
+	return ProcessorFunc(func(buf []float64, start int64) {
+		for i := range buf {
+			buf[i] = s.At(FrameTime(start+int64(i), rate))
+		}
+	})
+}
+
+// Like Sample, but for processors (using blocks of BlockSize frames).
+func Render(p Processor, rate int, from, to time.Duration) (frames []float64) {
+	frames = make([]float64, FrameCount(to, rate))
+	start := FrameAt(from, rate)
+	for i := 0; i < len(frames); i += BlockSize {
+		p.Process(frames[i:min(i+BlockSize, len(frames))], start+int64(i))
+	}
+	return frames
+}
+
+// Block version of Sine (for a constant frequency).
+func SineBlock(freq float64, rate int) Processor {
+	step := freq / float64(rate)
+	return ProcessorFunc(func(buf []float64, start int64) {
+		for i := range buf {
+			// The phase is computed from the frame index (rather than accumulated) to avoid drifting.
+			buf[i] = math.Sin(2 * math.Pi * frac(float64(start+int64(i))*step))
+		}
+	})
+}
+
+// Block version of Combine (silent without inputs).
+func CombineBlocks(inputs ...Processor) Processor {
+	var tmp []float64
+	return ProcessorFunc(func(buf []float64, start int64) {
+		clear(buf)
+		if len(inputs) == 0 {
+			return
+		}
+		if len(tmp) < len(buf) {
+			tmp = make([]float64, len(buf))
+		}
+		for _, in := range inputs {
+			in.Process(tmp[:len(buf)], start)
+			for i, v := range tmp[:len(buf)] {
+				buf[i] += v
+			}
+		}
+		for i := range buf {
+			buf[i] /= float64(len(inputs))
+		}
+	})
+}
+
+// Block version of Amplify.
+func AmplifyBlocks(v, by Processor) Processor {
+	var tmp []float64
+	return ProcessorFunc(func(buf []float64, start int64) {
+		if len(tmp) < len(buf) {
+			tmp = make([]float64, len(buf))
+		}
+		v.Process(buf, start)
+		by.Process(tmp[:len(buf)], start)
+		for i := range buf {
+			buf[i] *= tmp[i]
+		}
+	})
+}
+
+// Block version of LowPass (for a constant cutoff and Q factor).
+// Like other stateful processors, blocks must be processed in order (the state is reset otherwise).
+func LowPassBlock(in Processor, rate int, cutoff, q float64) Processor {
+	cos, alpha := rbj(0, rate, Constant(cutoff), Constant(q))
+	b0, b1, b2 := (1-cos)/2/(1+alpha), (1-cos)/(1+alpha), (1-cos)/2/(1+alpha)
+	a1, a2 := -2*cos/(1+alpha), (1-alpha)/(1+alpha)
+	var x1, x2, y1, y2 float64
+	next := int64(0)
+	return ProcessorFunc(func(buf []float64, start int64) {
+		if start != next {
+			x1, x2, y1, y2 = 0, 0, 0, 0
+		}
+		in.Process(buf, start)
+		for i, x0 := range buf {
+			y := b0*x0 + b1*x1 + b2*x2 - a1*y1 - a2*y2
+			x1, x2 = x0, x1
+			y1, y2 = y, y1
+			buf[i] = y
+		}
+		next = start + int64(len(buf))
+	})
+}
@@ -0,0 +1,21 @@
+package dsp
+
+import (
+	"testing"
+	"time"
+)
+
+// Compares rendering Sine → LowPass → Amplify one frame at a time (Sample) and a block at a time (Render).
+func BenchmarkSample(b *testing.B) {
+	for range b.N {
+		s := Amplify(LowPass(Sine(Constant(440)), 44100, Constant(2000), Constant(0.707)), Constant(0.5))
+		Sample(s, 44100, 0, time.Second)
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	for range b.N {
+		p := AmplifyBlocks(LowPassBlock(SineBlock(440, 44100), 44100, 2000, 0.707), Block(Constant(0.5), 44100))
+		Render(p, 44100, 0, time.Second)
+	}
+}
@@ -0,0 +1,67 @@
+package dsp
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Frames sampled at a given rate, usable as a signal (which is 0 outside of the frames).
+// Values between frames are linearly interpolated.
+type Buffer struct {
+	Frames []float64
+	Rate   int
+}
+
+func (b Buffer) At(x time.Duration) (y float64) {
+	if i := FrameAt(x, b.Rate); FrameTime(i, b.Rate) == x {
+		if i < 0 || i >= int64(len(b.Frames)) {
+			return 0
+		}
+		return b.Frames[i]
+	}
+	return LinearInterpolation.At(b.Frames, x.Seconds()*float64(b.Rate), false)
+}
+
+func (b Buffer) Duration() time.Duration { return FrameTime(int64(len(b.Frames)), b.Rate) }
+
+// Returns the buffer as a finite signal (lasting as long as the buffer).
+func (b Buffer) Finite() FiniteSignal { return F(b.Duration(), b) }
+
+// Renders the signal from 0 to d into a buffer (the first time it's accessed),
+// and then plays it back from the buffer.
+// This avoids recomputing expensive signals that are played many times.
+func Freeze(s Signal, rate int, d time.Duration) FiniteSignal {
+	var once sync.Once
+	var b Buffer
+	return F(d, SignalFunc(func(x time.Duration) (y float64) {
+		once.Do(func() { b = Buffer{Sample(s, rate, 0, d), rate} })
+		return b.At(x)
+	}))
+}
+
+// Plays the signal backwards, from its last frame to its first (ex: for reversed cymbals).
+// Like Freeze, the signal is rendered into a buffer the first time it's accessed,
+// so stateful signals (filters, delays, envelopes, ...) can be reversed too.
+func Reverse(s FiniteSignal, rate int) FiniteSignal {
+	var once sync.Once
+	var b Buffer
+	return F(s.Duration, SignalFunc(func(x time.Duration) (y float64) {
+		once.Do(func() { b = Buffer{Sample(s, rate, 0, s.Duration), rate}.Reverse() })
+		return b.At(x)
+	}))
+}
+
+// Returns the frames between from and to (sharing the same memory), ex: to slice a loop at its onsets.
+func (b Buffer) Slice(from, to time.Duration) Buffer {
+	n := int64(len(b.Frames))
+	i, j := min(max(FrameAt(from, b.Rate), 0), n), min(max(FrameAt(to, b.Rate), 0), n)
+	return Buffer{Frames: b.Frames[i:max(i, j)], Rate: b.Rate}
+}
+
+// Returns a copy of the buffer with the frames in reverse order.
+func (b Buffer) Reverse() Buffer {
+	frames := slices.Clone(b.Frames)
+	slices.Reverse(frames)
+	return Buffer{Frames: frames, Rate: b.Rate}
+}
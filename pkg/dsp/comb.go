@@ -0,0 +1,52 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Building blocks of reverbs, flangers and physical models (ex: a feedback comb filter is a plucked string without damping).
+// The delay (in seconds) can be modulated, between one frame and maxDelay.
+
+// Feedforward comb filter: adds a delayed copy of the input, y[n] = x[n] + gain·x[n-d].
+// Creates notches at odd multiples of 1/(2d) Hz (with a positive gain).
+func FeedforwardComb(in Signal, rate int, delay Signal, maxDelay time.Duration, gain float64) Signal {
+	return delayFilter(in, rate, delay, maxDelay, func(line *delayLine, x0, d float64) (y float64) {
+		y = x0 + gain*line.read(d)
+		line.write(x0)
+		return y
+	})
+}
+
+// Feedback comb filter: feeds the delayed output back into the input, y[n] = x[n] + gain·y[n-d].
+// Creates resonant peaks at multiples of 1/d Hz (with a positive gain), gain must stay between -1 and 1 to be stable.
+func FeedbackComb(in Signal, rate int, delay Signal, maxDelay time.Duration, gain float64) Signal {
+	return delayFilter(in, rate, delay, maxDelay, func(line *delayLine, x0, d float64) (y float64) {
+		y = x0 + gain*line.read(d)
+		line.write(y)
+		return y
+	})
+}
+
+// Schroeder allpass filter: y[n] = -gain·x[n] + x[n-d] + gain·y[n-d].
+// All frequencies pass with the same level but with different delays, which smears transients (ex: reverb diffusion).
+func Allpass(in Signal, rate int, delay Signal, maxDelay time.Duration, gain float64) Signal {
+	return delayFilter(in, rate, delay, maxDelay, func(line *delayLine, x0, d float64) (y float64) {
+		delayed := line.read(d)
+		v := x0 + gain*delayed
+		line.write(v)
+		return -gain*v + delayed
+	})
+}
+
+// Runs a filter built around a delay line, with the delay converted to frames (and clamped).
+func delayFilter(in Signal, rate int, delay Signal, maxDelay time.Duration, step func(line *delayLine, x0, d float64) float64) Signal {
+	maxFrames := maxDelay.Seconds() * float64(rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		line := newDelayLine(int(maxFrames) + 2)
+		return func(x time.Duration) (y float64) {
+			d := math.Max(1, math.Min(maxFrames, delay.At(x)*float64(rate)))
+			return step(line, in.At(x), d)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Returns the gains of the outgoing and incoming signals at a given position (between 0 and 1) of a crossfade.
+type FadeCurve func(t float64) (out, in float64)
+
+var (
+	// Gains sum to 1, there is a dip in loudness halfway through when the signals are unrelated.
+	LinearFade FadeCurve = func(t float64) (out, in float64) { return 1 - t, t }
+
+	// Keeps a constant loudness when the signals are unrelated (gains squared sum to 1).
+	EqualPowerFade FadeCurve = func(t float64) (out, in float64) {
+		return math.Cos(t * math.Pi / 2), math.Sin(t * math.Pi / 2)
+	}
+)
+
+// Fades from a to b over the given duration.
+// Like Lerp, the position in the fade is x modulo over, so it can be placed between two segments of a Sequence.
+//
+// Ex: dsp.Sequence(dsp.F(bpm.T(4), a), dsp.Crossfade(a, b, bpm.T(1), dsp.EqualPowerFade), dsp.F(bpm.T(4), b))
+func Crossfade(a, b Signal, over time.Duration, curve FadeCurve) FiniteSignal {
+	progress := Lerp(0, 1, over)
+	return F(over, SignalFunc(func(x time.Duration) (y float64) {
+		out, in := curve(progress.At(x))
+		return out*a.At(x) + in*b.At(x)
+	}))
+}
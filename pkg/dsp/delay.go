@@ -0,0 +1,64 @@
+package dsp
+
+import (
+	"time"
+)
+
+// Feedback delay (echo), mix goes from 0 (dry signal only) to 1 (delayed signal only).
+func Delay(in Signal, rate int, delayTime time.Duration, feedback, mix float64) Signal {
+	n := delayTime.Seconds() * float64(rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		line := newDelayLine(int(n) + 2)
+		return func(x time.Duration) (y float64) {
+			dry := in.At(x)
+			wet := line.read(n)
+			line.write(dry + feedback*wet)
+			return (1-mix)*dry + mix*wet
+		}
+	})
+}
+
+// Circular buffer holding the most recent frames of a signal.
+type delayLine struct {
+	buf []float64
+	pos int // Index of the next write
+}
+
+func newDelayLine(size int) *delayLine { return &delayLine{buf: make([]float64, max(size, 1))} }
+
+func (d *delayLine) write(v float64) {
+	d.buf[d.pos] = v
+	d.pos = (d.pos + 1) % len(d.buf)
+}
+
+// Returns the value written n frames before the next write (n can be fractional).
+func (d *delayLine) read(n float64) float64 {
+	return LinearInterpolation.At(d.buf, float64(d.pos)-n, true)
+}
+
+// Chorus effect: the signal is mixed with a copy of itself whose delay slowly varies (around 20ms),
+// speed is the modulation frequency (in Hertz) and depth (between 0 and 1) the amount of modulation.
+func Chorus(in Signal, rate int, speed, depth, feedback, mix float64) Signal {
+	return modulatedDelay(in, rate, 20*time.Millisecond, time.Duration(depth*float64(8*time.Millisecond)), speed, feedback, mix)
+}
+
+// Flanger effect: like Chorus but with much shorter delays (from 0 to 5ms),
+// which creates a sweeping comb filter (especially with some feedback).
+func Flanger(in Signal, rate int, speed, depth, feedback, mix float64) Signal {
+	sweep := time.Duration(depth * float64(5*time.Millisecond) / 2)
+	return modulatedDelay(in, rate, sweep+100*time.Microsecond, sweep, speed, feedback, mix)
+}
+
+// Feedback delay whose delay time oscillates between center-swing and center+swing.
+func modulatedDelay(in Signal, rate int, center, swing time.Duration, speed, feedback, mix float64) Signal {
+	lfo := LFO(Sine, Constant(speed), Constant(swing.Seconds()*float64(rate)), Constant(center.Seconds()*float64(rate)))
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		line := newDelayLine(int((center+swing).Seconds()*float64(rate)) + 2)
+		return func(x time.Duration) (y float64) {
+			dry := in.At(x)
+			wet := line.read(lfo.At(x))
+			line.write(dry + feedback*wet)
+			return (1-mix)*dry + mix*wet
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Transfer function used to shape a signal.
+type Curve func(v float64) float64
+
+var (
+	// Smooth saturation.
+	Tanh Curve = math.Tanh
+	// Cuts everything above 1 (and below -1).
+	HardClip Curve = func(v float64) float64 { return math.Max(-1, math.Min(1, v)) }
+	// Gentle saturation (x - x³/3, scaled to reach 1 at 1), cuts everything above 1 (and below -1).
+	Cubic Curve = func(v float64) float64 {
+		v = math.Max(-1, math.Min(1, v))
+		return 1.5*v - 0.5*v*v*v
+	}
+	// Reflects everything above 1 (and below -1) back into range.
+	Foldback Curve = func(v float64) float64 { return 1 - math.Abs(4*frac((v+1)/4)-2) }
+)
+
+// Waveshaping distortion: the signal is amplified by drive and then passed through the given curve.
+func Distort(in Signal, drive float64, shape Curve) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return shape(drive * in.At(x))
+	})
+}
+
+// Soft clipper: the signal is left untouched between -knee and knee (from 0 to 1),
+// and values beyond are smoothly squashed so that they never reach -1 or 1.
+// Unlike Distort, quiet passages are unaffected, it only rounds off the peaks that would otherwise clip.
+func SoftClip(in Signal, knee float64) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		v := in.At(x)
+		if a := math.Abs(v); a > knee {
+			return math.Copysign(knee+(1-knee)*math.Tanh((a-knee)/(1-knee)), v)
+		}
+		return v
+	})
+}
+
+// Passes the signal through unchanged, calling onClip for each value beyond [-1, 1]
+// (such values are clipped when encoded to integer formats).
+func DetectClips(in Signal, onClip func(x time.Duration, y float64)) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		y = in.At(x)
+		if y > 1 || y < -1 {
+			onClip(x, y)
+		}
+		return y
+	})
+}
+
+// Lo-fi effect: the signal is held at a lower sample rate (in Hertz)
+// and its amplitude is quantized to the given number of bits.
+func Bitcrush(in Signal, bits, rate Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		r := math.Max(1, rate.At(x))
+		held := time.Duration(math.Floor(x.Seconds()*r) / r * float64(time.Second))
+		levels := math.Pow(2, math.Max(1, bits.At(x))-1)
+		return math.Round(in.At(held)*levels) / levels
+	})
+}
@@ -0,0 +1,139 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Dynamics compressor: the level of the signal is reduced once it goes above the threshold (in dBFS),
+// by the given ratio (ex: 4 means that 4 dB above the threshold only come out as 1 dB).
+// Attack and release control how fast the compressor reacts when the level goes up and down.
+func Compress(in Signal, rate int, threshold, ratio float64, attack, release time.Duration) Signal {
+	return sidechain(in, nil, rate, threshold, ratio, attack, release)
+}
+
+// Sidechain compressor ("ducking"): like Compress, but the level of in is reduced
+// when the key signal goes above the threshold (ex: pads pumping against a kick drum).
+//
+// Ex: dsp.Duck(pad, kick, rate, -24, 8, time.Millisecond, 200*time.Millisecond)
+func Duck(in, key Signal, rate int, threshold, ratio float64, attack, release time.Duration) Signal {
+	return sidechain(in, key, rate, threshold, ratio, attack, release)
+}
+
+// Compresses in based on the level of key (or of in itself if key is nil).
+func sidechain(in, key Signal, rate int, threshold, ratio float64, attack, release time.Duration) Signal {
+	a, r := smoothing(attack, rate), smoothing(release, rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var env float64
+		return func(x time.Duration) (y float64) {
+			v := in.At(x)
+			level := math.Abs(v)
+			if key != nil {
+				level = math.Abs(key.At(x))
+			}
+			if level > env {
+				env = a*env + (1-a)*level
+			} else {
+				env = r*env + (1-r)*level
+			}
+			if over := toDB(env) - threshold; over > 0 {
+				v *= fromDB(-over * (1 - 1/ratio))
+			}
+			return v
+		}
+	})
+}
+
+// Noise gate: the signal is muted while its level stays below the threshold (in dBFS).
+// The gate opens over the attack time when the level goes above the threshold,
+// stays open for the hold time after the level drops, then closes over the release time.
+func Gate(in Signal, rate int, threshold float64, attack, hold, release time.Duration) Signal {
+	open := fromDB(threshold)
+	decay := smoothing(5*time.Millisecond, rate) // Keeps the level from dropping at each zero crossing
+	up, down := ramp(attack, rate), ramp(release, rate)
+	holdFrames := int64(hold.Seconds() * float64(rate))
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var env, gain float64
+		var below int64 // Number of frames since the level went below the threshold
+		return func(x time.Duration) (y float64) {
+			v := in.At(x)
+			env = math.Max(math.Abs(v), env*decay)
+			if env >= open {
+				below = 0
+				gain = math.Min(1, gain+up)
+			} else if below++; below > holdFrames {
+				gain = math.Max(0, gain-down)
+			}
+			return v * gain
+		}
+	})
+}
+
+// Returns the step of a linear ramp going from 0 to 1 over d.
+func ramp(d time.Duration, rate int) float64 {
+	if d <= 0 {
+		return 1
+	}
+	return 1 / (d.Seconds() * float64(rate))
+}
+
+// Duration of the lookahead used by Limit.
+const LimiterLookahead = 5 * time.Millisecond
+
+// Brickwall limiter: the output never goes above the ceiling (in dBFS).
+// The gain is reduced smoothly ahead of peaks (see LimiterLookahead) and recovers over the release time.
+func Limit(in Signal, rate int, ceiling float64, release time.Duration) Signal {
+	peak := fromDB(ceiling)
+	r := smoothing(release, rate)
+	size := max(int(LimiterLookahead.Seconds()*float64(rate)), 1)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		// Upcoming input frames and their target gains, and past minimum gains (for smoothing).
+		frames, targets, holds := make([]float64, size), make([]float64, size), make([]float64, size)
+		for i := range targets {
+			targets[i], holds[i] = 1, 1
+		}
+		var n int64
+		var pos int
+		sum, gain := float64(size), 1.0
+		read := func(i int64) {
+			v := in.At(FrameTime(i, rate))
+			frames[pos], targets[pos] = v, math.Min(1, peak/math.Abs(v))
+			pos = (pos + 1) % size
+		}
+		return func(x time.Duration) (y float64) {
+			if n == 0 {
+				for i := range int64(size - 1) {
+					read(i)
+				}
+			}
+			read(n + int64(size) - 1) // The current frame is now frames[pos]
+
+			hold := 1.0
+			for _, t := range targets {
+				hold = math.Min(hold, t)
+			}
+			sum += hold - holds[pos]
+			holds[pos] = hold
+			smoothed := math.Min(targets[pos], sum/float64(size)) // min() guards against rounding errors
+
+			if smoothed < gain {
+				gain = smoothed
+			} else {
+				gain = smoothed + r*(gain-smoothed)
+			}
+			n++
+			return frames[pos] * gain
+		}
+	})
+}
+
+// Returns the coefficient of a one-pole smoothing filter reaching ~63% of its target after d.
+func smoothing(d time.Duration, rate int) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (d.Seconds() * float64(rate)))
+}
+
+func toDB(gain float64) float64 { return 20 * math.Log10(gain) }
+func fromDB(db float64) float64 { return math.Pow(10, db/20) }
@@ -0,0 +1,216 @@
+package dsp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// Sample format used to encode audio frames.
+type SampleFormat int
+
+const (
+	Float64 SampleFormat = iota
+	Float32
+	Int16
+	Int24
+	Int32
+	Uint8
+)
+
+// Returns the size (in bytes) of a single encoded frame.
+func (f SampleFormat) Size() int {
+	switch f {
+	case Float64:
+		return 8
+	case Float32, Int32:
+		return 4
+	case Int24:
+		return 3
+	case Int16:
+		return 2
+	case Uint8:
+		return 1
+	}
+	panic(fmt.Errorf("unknown sample format: %d", f))
+}
+
+func (f SampleFormat) IsFloat() bool { return f == Float64 || f == Float32 }
+
+type EncodeOptions struct {
+	Format       SampleFormat // Defaults to Float64
+	Dither       Dither       // Only applies to integer formats, defaults to NoDither
+	LittleEndian bool         // Byte order of raw PCM (ex: for "sox -t f32" or "ffmpeg -f f32le"), WAV and AIFF files have their own
+	RemoveDC     bool         // Removes the DC offset before encoding (like DCBlock)
+}
+
+// Noise added before reducing the resolution of frames (to integer formats).
+// Without dither, the rounding error follows the signal and sounds like distortion on quiet passages,
+// with dither it becomes a constant (and much less noticeable) hiss.
+type Dither int
+
+const (
+	NoDither    Dither = iota
+	TPDF               // Triangular noise of ±1 least significant bit
+	NoiseShaped        // TPDF, with the rounding error pushed towards high frequencies (where the ear is less sensitive)
+)
+
+// Encodes frames as raw PCM, big-endian (as expected by "ffplay -f f64be", "-f s16be", etc.)
+// unless opts.LittleEndian is set. To encode a stream one chunk at a time, use a PCMEncoder.
+func EncodePCM(frames []float64, rate int, opts EncodeOptions) (b []byte) {
+	return NewPCMEncoder(rate, opts).Encode(frames)
+}
+
+// Encodes a stream as raw PCM (like EncodePCM) one chunk at a time,
+// keeping the state of the dither and of the DC offset removal between chunks.
+type PCMEncoder struct{ e *encoder }
+
+func NewPCMEncoder(rate int, opts EncodeOptions) *PCMEncoder {
+	return &PCMEncoder{newEncoder(opts, opts.byteOrder(), rate)}
+}
+
+// Encodes the next frames of the stream.
+func (p *PCMEncoder) Encode(frames []float64) (b []byte) {
+	b = make([]byte, 0, len(frames)*p.e.format.Size())
+	for _, pulse := range frames {
+		b = p.e.append(b, pulse)
+	}
+	return b
+}
+
+// Decodes raw PCM encoded with the given format and byte order (the inverse of EncodePCM), a trailing partial frame is ignored.
+// Raw PCM doesn't say how it's encoded nor its sample rate, which must be known beforehand.
+func DecodePCM(b []byte, opts EncodeOptions) (frames []float64) {
+	var order binary.ByteOrder = binary.BigEndian
+	if opts.LittleEndian {
+		order = binary.LittleEndian
+	}
+	size := opts.Format.Size()
+	frames = make([]float64, len(b)/size)
+	for i := range frames {
+		frames[i] = decodeFrame(b[i*size:], order, opts.Format)
+	}
+	return frames
+}
+
+// Returns the byte order of raw PCM.
+func (opts EncodeOptions) byteOrder() binary.AppendByteOrder {
+	if opts.LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Encodes frames one after the other, keeping the state of the dither.
+type encoder struct {
+	format SampleFormat
+	order  binary.AppendByteOrder
+	dither Dither
+	noise  *rand.Rand
+	e1, e2 float64 // Last rounding errors (in least significant bits)
+
+	removeDC bool
+	pole     float64
+	x1, y1   float64 // Last input and output of the DC blocker
+}
+
+func newEncoder(opts EncodeOptions, order binary.AppendByteOrder, rate int) *encoder {
+	return &encoder{
+		format:   opts.Format,
+		order:    order,
+		dither:   opts.Dither,
+		noise:    rand.New(rand.NewPCG(1, 2)), // Seeded so that renders are reproducible
+		removeDC: opts.RemoveDC,
+		pole:     dcPole(rate),
+	}
+}
+
+func (e *encoder) append(b []byte, pulse float64) []byte {
+	return appendFrame(b, e.order, e.format, e.next(pulse))
+}
+
+// Returns the frame to encode (without the DC offset, and dithered).
+func (e *encoder) next(pulse float64) float64 {
+	if e.removeDC {
+		y := pulse - e.x1 + e.pole*e.y1
+		e.x1, e.y1 = pulse, y
+		pulse = y
+	}
+	if e.dither == NoDither || e.format.IsFloat() {
+		return pulse
+	}
+	scale := e.format.scale()
+	v := pulse * scale
+	if e.dither == NoiseShaped {
+		v -= 2*e.e1 - e.e2 // Second order error feedback: the error is filtered by (1 - z⁻¹)²
+	}
+	q := math.Round(v + e.noise.Float64() - e.noise.Float64())
+	e.e1, e.e2 = q-v, e.e1
+	return q / scale
+}
+
+// Returns the value encoding 1 in integer formats.
+func (f SampleFormat) scale() float64 {
+	switch f {
+	case Int16:
+		return math.MaxInt16
+	case Int24:
+		return 1<<23 - 1
+	case Int32:
+		return math.MaxInt32
+	case Uint8:
+		return 127
+	}
+	panic(fmt.Errorf("not an integer sample format: %d", f))
+}
+
+// Appends a single frame to b.
+// Integer formats are scaled from [-1, 1] and clamped, float formats are written as is.
+func appendFrame(b []byte, order binary.AppendByteOrder, f SampleFormat, pulse float64) []byte {
+	if !f.IsFloat() {
+		pulse = math.Max(-1, math.Min(1, pulse))
+	}
+	switch f {
+	case Float64:
+		return order.AppendUint64(b, math.Float64bits(pulse))
+	case Float32:
+		return order.AppendUint32(b, math.Float32bits(float32(pulse)))
+	case Int16:
+		return order.AppendUint16(b, uint16(int16(math.Round(pulse*math.MaxInt16))))
+	case Int24:
+		v := uint32(int32(math.Round(pulse * (1<<23 - 1))))
+		if order == binary.BigEndian {
+			return append(b, byte(v>>16), byte(v>>8), byte(v))
+		}
+		return append(b, byte(v), byte(v>>8), byte(v>>16))
+	case Int32:
+		return order.AppendUint32(b, uint32(int32(math.Round(pulse*math.MaxInt32))))
+	case Uint8:
+		return append(b, uint8(math.Round(pulse*127)+128))
+	}
+	panic(fmt.Errorf("unknown sample format: %d", f))
+}
+
+// Decodes a single frame (the inverse of appendFrame).
+func decodeFrame(b []byte, order binary.ByteOrder, f SampleFormat) (pulse float64) {
+	switch f {
+	case Float64:
+		return math.Float64frombits(order.Uint64(b))
+	case Float32:
+		return float64(math.Float32frombits(order.Uint32(b)))
+	case Int16:
+		return float64(int16(order.Uint16(b))) / math.MaxInt16
+	case Int24:
+		v := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+		if order == binary.BigEndian {
+			v = uint32(b[2]) | uint32(b[1])<<8 | uint32(b[0])<<16
+		}
+		return float64(int32(v<<8)>>8) / (1<<23 - 1)
+	case Int32:
+		return float64(int32(order.Uint32(b))) / math.MaxInt32
+	case Uint8:
+		return (float64(b[0]) - 128) / 127
+	}
+	panic(fmt.Errorf("unknown sample format: %d", f))
+}
@@ -0,0 +1,47 @@
+package dsp
+
+import (
+	"time"
+)
+
+// Attack-decay-sustain-release envelope: the gain rises to 1 (attack), falls to the sustain level (decay)
+// and stays there while the note is held, then falls to 0 once the note is released (release).
+type ADSR struct {
+	Attack  time.Duration
+	Decay   time.Duration
+	Sustain float64 // Between 0 and 1
+	Release time.Duration
+}
+
+// Returns the envelope of a note held for the given duration (the envelope lasts until the end of the release).
+//
+// Ex: dsp.Amplify(dsp.Saw(music.A4), dsp.ADSR{10 * time.Millisecond, 100 * time.Millisecond, 0.6, time.Second}.Note(bpm.T(1)))
+func (e ADSR) Note(length time.Duration) FiniteSignal {
+	return F(length+e.Release, SignalFunc(func(x time.Duration) (y float64) {
+		return e.Gain(x, length)
+	}))
+}
+
+// Returns the gain at x for a note released at the given position (x and released are relative to the start of the note).
+// Use a negative released position for notes that are still held.
+func (e ADSR) Gain(x, released time.Duration) float64 {
+	if released < 0 || x < released {
+		return e.held(x)
+	}
+	if x >= released+e.Release {
+		return 0
+	}
+	return e.held(released) * (1 - float64(x-released)/float64(e.Release))
+}
+
+func (e ADSR) held(x time.Duration) float64 {
+	switch {
+	case x < 0:
+		return 0
+	case x < e.Attack:
+		return float64(x) / float64(e.Attack)
+	case x < e.Attack+e.Decay:
+		return 1 - (1-e.Sustain)*float64(x-e.Attack)/float64(e.Decay)
+	}
+	return e.Sustain
+}
@@ -0,0 +1,47 @@
+package dsp
+
+import "fmt"
+
+// Parametric equalizer: applies each band (a shelf or peaking filter) one after the other.
+//
+// Ex: less rumble, less mud, a bit more air
+//
+//	dsp.EQ(in, rate,
+//		dsp.EQBand{Shape: dsp.LowShelfBand, Freq: 80, Gain: -6},
+//		dsp.EQBand{Shape: dsp.PeakingBand, Freq: 300, Gain: -3, Q: 1.5},
+//		dsp.EQBand{Shape: dsp.HighShelfBand, Freq: 8000, Gain: 2},
+//	)
+func EQ(in Signal, rate int, bands ...EQBand) Signal {
+	for _, b := range bands {
+		q := b.Q
+		if q == 0 {
+			q = 0.707
+		}
+		switch b.Shape {
+		case LowShelfBand:
+			in = LowShelf(in, rate, Constant(b.Freq), Constant(q), Constant(b.Gain))
+		case PeakingBand:
+			in = Peaking(in, rate, Constant(b.Freq), Constant(q), Constant(b.Gain))
+		case HighShelfBand:
+			in = HighShelf(in, rate, Constant(b.Freq), Constant(q), Constant(b.Gain))
+		default:
+			panic(fmt.Errorf("unknown EQ band shape: %d", b.Shape))
+		}
+	}
+	return in
+}
+
+type EQBand struct {
+	Shape EQShape
+	Freq  float64 // Cutoff of shelves and center of peaking bands (in Hertz)
+	Gain  float64 // In dB
+	Q     float64 // Defaults to 0.707
+}
+
+type EQShape int
+
+const (
+	PeakingBand EQShape = iota
+	LowShelfBand
+	HighShelfBand
+)
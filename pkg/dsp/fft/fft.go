@@ -0,0 +1,99 @@
+// Package fft computes the spectrum of audio frames (Fast Fourier Transform),
+// as a basis for spectral analysis and effects.
+//
+// Lengths must be powers of 2 (functions panic otherwise), see Size to round lengths up.
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Returns the smallest power of 2 greater than or equal to n.
+func Size(n int) int {
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// Returns the discrete Fourier transform of x.
+func FFT(x []complex128) []complex128 {
+	out := append([]complex128(nil), x...)
+	transform(out, false)
+	return out
+}
+
+// Returns the inverse discrete Fourier transform of x (so that IFFT(FFT(x)) == x).
+func IFFT(x []complex128) []complex128 {
+	out := append([]complex128(nil), x...)
+	transform(out, true)
+	n := complex(float64(len(out)), 0)
+	for i := range out {
+		out[i] /= n
+	}
+	return out
+}
+
+// Returns the spectrum of real frames: len(x)/2+1 bins from 0Hz to the Nyquist frequency
+// (bin k is at k*rate/len(x) Hertz).
+func Real(x []float64) []complex128 {
+	buf := make([]complex128, len(x))
+	for i, v := range x {
+		buf[i] = complex(v, 0)
+	}
+	transform(buf, false)
+	return buf[:len(x)/2+1]
+}
+
+// Returns the n real frames of a spectrum returned by Real (the inverse of Real).
+func InverseReal(spectrum []complex128, n int) []float64 {
+	if len(spectrum) != n/2+1 {
+		panic(fmt.Errorf("spectrum of %d frames should have %d bins, got %d", n, n/2+1, len(spectrum)))
+	}
+	// The second half of the spectrum of real frames mirrors the first one.
+	buf := make([]complex128, n)
+	copy(buf, spectrum)
+	for k := n/2 + 1; k < n; k++ {
+		buf[k] = cmplx.Conj(spectrum[n-k])
+	}
+	buf = IFFT(buf)
+	out := make([]float64, n)
+	for i, v := range buf {
+		out[i] = real(v)
+	}
+	return out
+}
+
+// In-place iterative radix-2 FFT.
+func transform(buf []complex128, inverse bool) {
+	n := len(buf)
+	if n == 0 || n&(n-1) != 0 {
+		panic(fmt.Errorf("FFT length must be a power of 2, got %d", n))
+	}
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		for k := range size / 2 {
+			w := cmplx.Rect(1, sign*2*math.Pi*float64(k)/float64(size))
+			for start := 0; start < n; start += size {
+				a, b := buf[start+k], buf[start+k+size/2]*w
+				buf[start+k], buf[start+k+size/2] = a+b, a-b
+			}
+		}
+	}
+}
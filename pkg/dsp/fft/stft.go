@@ -0,0 +1,45 @@
+package fft
+
+// Short-time Fourier transform: returns the spectrum (see Real) of successive windowed slices of x,
+// each starting hop frames after the previous one (the size must be a power of 2).
+// Frames beyond the end of x are treated as 0.
+func STFT(x []float64, size, hop int, window Window) (spectra [][]complex128) {
+	w := window(size)
+	buf := make([]float64, size)
+	for start := 0; start < len(x) || start == 0; start += hop {
+		for i := range buf {
+			buf[i] = 0
+			if start+i < len(x) {
+				buf[i] = x[start+i] * w[i]
+			}
+		}
+		spectra = append(spectra, Real(buf))
+		if start+size >= len(x) {
+			break
+		}
+	}
+	return spectra
+}
+
+// Inverse short-time Fourier transform: overlaps and adds the frames of each spectrum
+// (which may have been modified), and returns n frames.
+// The window should be the one used by STFT, it's applied again and compensated for
+// (frames where the window is 0, like the first one with Hann, can't be recovered and are 0).
+func ISTFT(spectra [][]complex128, size, hop, n int, window Window) []float64 {
+	w := window(size)
+	out, weights := make([]float64, n), make([]float64, n)
+	for s, spectrum := range spectra {
+		for i, v := range InverseReal(spectrum, size) {
+			if j := s*hop + i; j < n {
+				out[j] += v * w[i]
+				weights[j] += w[i] * w[i]
+			}
+		}
+	}
+	for i, w := range weights {
+		if w > 1e-9 {
+			out[i] /= w
+		}
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package fft
+
+import (
+	"math"
+)
+
+// Returns the coefficients of a window function of the given size.
+// Frames are multiplied by a window before being analyzed, to reduce spectral leakage.
+type Window func(size int) []float64
+
+var (
+	Rectangular Window = func(size int) []float64 { return cosineWindow(size, 1) }
+	Hann        Window = func(size int) []float64 { return cosineWindow(size, 0.5, 0.5) }
+	Hamming     Window = func(size int) []float64 { return cosineWindow(size, 0.54, 0.46) }
+	Blackman    Window = func(size int) []float64 { return cosineWindow(size, 0.42, 0.5, 0.08) }
+)
+
+// Returns a (periodic) sum of cosines: a0 - a1*cos(2πi/size) + a2*cos(4πi/size) - ...
+func cosineWindow(size int, coefs ...float64) []float64 {
+	w := make([]float64, size)
+	for i := range w {
+		sign := 1.0
+		for k, a := range coefs {
+			w[i] += sign * a * math.Cos(2*math.Pi*float64(k*i)/float64(size))
+			sign = -sign
+		}
+	}
+	return w
+}
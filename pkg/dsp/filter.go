@@ -0,0 +1,154 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Biquad filters, using the coefficients from Robert Bristow-Johnson's "Audio EQ Cookbook".
+// The cutoff (in Hertz) and Q factor can be modulated over time.
+
+func LowPass(in Signal, rate int, cutoff, q Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, cutoff, q)
+		return (1 - cos) / 2, 1 - cos, (1 - cos) / 2, 1 + alpha, -2 * cos, 1 - alpha
+	})
+}
+
+func HighPass(in Signal, rate int, cutoff, q Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, cutoff, q)
+		return (1 + cos) / 2, -1 - cos, (1 + cos) / 2, 1 + alpha, -2 * cos, 1 - alpha
+	})
+}
+
+// Band-pass filter with a constant peak gain of 0 dB.
+func BandPass(in Signal, rate int, center, q Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, center, q)
+		return alpha, 0, -alpha, 1 + alpha, -2 * cos, 1 - alpha
+	})
+}
+
+func Notch(in Signal, rate int, center, q Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, center, q)
+		return 1, -2 * cos, 1, 1 + alpha, -2 * cos, 1 - alpha
+	})
+}
+
+// Peaking filter: boosts or cuts (by gain, in dB) the frequencies around the center, the higher q the narrower.
+func Peaking(in Signal, rate int, center, q, gain Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, center, q)
+		a := math.Pow(10, gain.At(x)/40)
+		return 1 + alpha*a, -2 * cos, 1 - alpha*a, 1 + alpha/a, -2 * cos, 1 - alpha/a
+	})
+}
+
+// Low shelf filter: boosts or cuts (by gain, in dB) the frequencies below the cutoff.
+// A q of 0.707 gives the steepest slope without overshoot.
+func LowShelf(in Signal, rate int, cutoff, q, gain Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, cutoff, q)
+		a := math.Pow(10, gain.At(x)/40)
+		sq := 2 * math.Sqrt(a) * alpha
+		return a * ((a + 1) - (a-1)*cos + sq), 2 * a * ((a - 1) - (a+1)*cos), a * ((a + 1) - (a-1)*cos - sq),
+			(a + 1) + (a-1)*cos + sq, -2 * ((a - 1) + (a+1)*cos), (a + 1) + (a-1)*cos - sq
+	})
+}
+
+// High shelf filter: boosts or cuts (by gain, in dB) the frequencies above the cutoff.
+// A q of 0.707 gives the steepest slope without overshoot.
+func HighShelf(in Signal, rate int, cutoff, q, gain Signal) Signal {
+	return biquad(in, rate, func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		cos, alpha := rbj(x, rate, cutoff, q)
+		a := math.Pow(10, gain.At(x)/40)
+		sq := 2 * math.Sqrt(a) * alpha
+		return a * ((a + 1) + (a-1)*cos + sq), -2 * a * ((a - 1) + (a+1)*cos), a * ((a + 1) + (a-1)*cos - sq),
+			(a + 1) - (a-1)*cos + sq, 2 * ((a - 1) - (a+1)*cos), (a + 1) - (a-1)*cos - sq
+	})
+}
+
+func biquad(in Signal, rate int, coefs func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64)) Signal {
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var x1, x2, y1, y2 float64
+		return func(x time.Duration) (y float64) {
+			b0, b1, b2, a0, a1, a2 := coefs(x)
+			x0 := in.At(x)
+			y = (b0*x0 + b1*x1 + b2*x2 - a1*y1 - a2*y2) / a0
+			x1, x2 = x0, x1
+			y1, y2 = y, y1
+			return y
+		}
+	})
+}
+
+// Returns cos(w0) and alpha for the given frequency and Q factor (clamped to a stable range).
+func rbj(x time.Duration, rate int, freq, q Signal) (cos, alpha float64) {
+	f := math.Max(1, math.Min(0.49*float64(rate), freq.At(x)))
+	w0 := 2 * math.Pi * f / float64(rate)
+	return math.Cos(w0), math.Sin(w0) / (2 * math.Max(q.At(x), 0.01))
+}
+
+// Outputs of a state-variable filter, they all share the same state.
+type StateVariableFilter struct {
+	LowPass, BandPass, HighPass, Notch Signal
+}
+
+// Resonant state-variable filter (trapezoidal integration, from Andrew Simper's "SvfLinearTrapOptimised2"),
+// it stays stable while its cutoff (in Hertz) and resonance (between 0 and 1) are modulated (ex: filter sweeps).
+func StateVariable(in Signal, rate int, cutoff, resonance Signal) StateVariableFilter {
+	var low, band, high float64
+	core := Stateful(rate, func() func(x time.Duration) float64 {
+		var ic1, ic2 float64
+		return func(x time.Duration) (y float64) {
+			f := math.Max(1, math.Min(0.49*float64(rate), cutoff.At(x)))
+			g := math.Tan(math.Pi * f / float64(rate))
+			k := 2 - 2*math.Max(0, math.Min(0.99, resonance.At(x)))
+			a1 := 1 / (1 + g*(g+k))
+			a2 := g * a1
+			a3 := g * a2
+
+			v0 := in.At(x)
+			v3 := v0 - ic2
+			v1 := a1*ic1 + a2*v3
+			v2 := ic2 + a2*ic1 + a3*v3
+			ic1, ic2 = 2*v1-ic1, 2*v2-ic2
+			low, band, high = v2, v1, v0-k*v1-v2
+			return low
+		}
+	})
+	output := func(v *float64) Signal {
+		return SignalFunc(func(x time.Duration) (y float64) { core.At(x); return *v })
+	}
+	return StateVariableFilter{
+		LowPass:  core,
+		BandPass: output(&band),
+		HighPass: output(&high),
+		Notch: SignalFunc(func(x time.Duration) (y float64) {
+			low := core.At(x) // Updates high, which must be read after
+			return low + high
+		}),
+	}
+}
+
+// Cutoff frequency of DCBlock (in Hertz).
+const DCBlockCutoff = 10
+
+// Removes the DC offset (0Hz component) of the signal with a one-pole high-pass filter,
+// ex: after asymmetric waveshaping or pulse waves with a duty cycle other than 0.5.
+func DCBlock(in Signal, rate int) Signal {
+	pole := dcPole(rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var x1, y1 float64
+		return func(x time.Duration) (y float64) {
+			x0 := in.At(x)
+			y = x0 - x1 + pole*y1
+			x1, y1 = x0, y
+			return y
+		}
+	})
+}
+
+func dcPole(rate int) float64 { return math.Exp(-2 * math.Pi * DCBlockCutoff / float64(rate)) }
@@ -0,0 +1,251 @@
+package dsp
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"math"
+)
+
+// Number of frames per channel in each block of the FLAC files written by EncodeFLAC.
+const FLACBlockSize = 4096
+
+// Encodes frames as a FLAC file (lossless compression, usually about half the size of the WAV file).
+// When channels > 1, frames must be interleaved (L, R, L, R, ...).
+// FLAC only stores integers: float formats are encoded as 24-bit frames.
+//
+// Each block is predicted with the best of FLAC's fixed polynomial predictors (orders 0 to 4),
+// and the prediction errors are stored with Rice codes.
+func EncodeFLAC(frames []float64, rate, channels int, opts EncodeOptions) (b []byte) {
+	if opts.Format.IsFloat() {
+		opts.Format = Int24
+	}
+	bps := opts.Format.Size() * 8
+	scale := opts.Format.scale()
+	count := len(frames) / channels
+
+	// Integer frames of each channel.
+	e := newEncoder(opts, binary.LittleEndian, rate)
+	samples := make([][]int64, channels)
+	for c := range samples {
+		samples[c] = make([]int64, count)
+	}
+	sum := md5.New() // Of the frames, as signed little-endian integers
+	raw := make([]byte, 0, opts.Format.Size())
+	for i := range count * channels {
+		v := int64(math.Round(math.Max(-1, math.Min(1, e.next(frames[i]))) * scale))
+		samples[i%channels][i/channels] = v
+		raw = raw[:0]
+		for j := range opts.Format.Size() {
+			raw = append(raw, byte(v>>(8*j)))
+		}
+		sum.Write(raw)
+	}
+
+	w := &bitWriter{b: []byte("fLaC")}
+	w.write(1, 1)              // Last metadata block
+	w.write(0, 7)              // STREAMINFO
+	w.write(34, 24)            // Size
+	w.write(FLACBlockSize, 16) // Minimum block size
+	w.write(FLACBlockSize, 16) // Maximum block size
+	w.write(0, 24)             // Minimum frame size (unknown)
+	w.write(0, 24)             // Maximum frame size (unknown)
+	w.write(uint64(rate), 20)
+	w.write(uint64(channels-1), 3)
+	w.write(uint64(bps-1), 5)
+	w.write(uint64(count)>>32, 4)
+	w.write(uint64(count)&0xFFFFFFFF, 32)
+	w.b = append(w.b, sum.Sum(nil)...)
+
+	for n, start := 0, 0; start < count; n, start = n+1, start+FLACBlockSize {
+		end := min(start+FLACBlockSize, count)
+		frame := len(w.b)
+		w.write(0xFFF8, 16)                    // Sync code, fixed block size
+		w.write(0b0111, 4)                     // Block size stored at the end of the header (16 bits)
+		w.write(0, 4)                          // Sample rate from STREAMINFO
+		w.write(uint64(channels-1), 4)         // Independent channels
+		w.write(0, 3)                          // Sample size from STREAMINFO
+		w.write(0, 1)                          // Reserved
+		w.b = appendFLACNumber(w.b, uint64(n)) // Frame number
+		w.write(uint64(end-start-1), 16)
+		w.write(uint64(crc8(w.b[frame:])), 8)
+		for c := range channels {
+			w.subframe(samples[c][start:end], bps)
+		}
+		w.align()
+		w.write(uint64(crc16(w.b[frame:])), 16)
+	}
+	return w.b
+}
+
+// Writes a subframe: a constant, the prediction errors of a fixed predictor, or the frames as is (whichever is smallest).
+func (w *bitWriter) subframe(x []int64, bps int) {
+	constant := true
+	for _, v := range x {
+		constant = constant && v == x[0]
+	}
+	if constant {
+		w.write(0, 8) // Padding, CONSTANT, no wasted bits
+		w.writeSigned(x[0], bps)
+		return
+	}
+
+	// Residuals of the fixed predictor of order k are the k-th differences of the frames.
+	best, bestBits := -1, bps*len(x)
+	var bestResiduals []int64
+	var bestParams []int
+	var bestOrder int
+	residuals := x
+	for order := 0; order <= 4 && order < len(x); order++ {
+		if order > 0 {
+			diff := make([]int64, len(residuals))
+			for i := order; i < len(x); i++ {
+				diff[i] = residuals[i] - residuals[i-1]
+			}
+			residuals = diff
+		}
+		params, partitionOrder, bits := riceParams(residuals[order:], len(x), order)
+		if bits < 0 {
+			continue // Prediction errors too large for decoders
+		}
+		bits += order * bps
+		if bits < bestBits {
+			best, bestBits, bestResiduals, bestParams, bestOrder = order, bits, residuals[order:], params, partitionOrder
+		}
+	}
+
+	if best < 0 {
+		w.write(0b00000010, 8) // Padding, VERBATIM, no wasted bits
+		for _, v := range x {
+			w.writeSigned(v, bps)
+		}
+		return
+	}
+	w.write(uint64(0b00010000|best<<1), 8) // Padding, FIXED with the order, no wasted bits
+	for _, v := range x[:best] {
+		w.writeSigned(v, bps) // Warm-up frames
+	}
+	w.write(0b01, 2) // Rice codes with 5-bit parameters
+	w.write(uint64(bestOrder), 4)
+	partition := len(x) >> bestOrder
+	for p, k := range bestParams {
+		from, to := max(0, p*partition-best), (p+1)*partition-best
+		w.write(uint64(k), 5)
+		for _, r := range bestResiduals[from:to] {
+			u := uint64(r<<1) ^ uint64(r>>63) // Zigzag: 0, -1, 1, -2, 2, ...
+			w.unary(u >> k)
+			w.write(u&(1<<k-1), k)
+		}
+	}
+}
+
+// Returns the Rice parameter of each partition of the residuals (for the best partition order),
+// and the number of bits it takes to write them, or -1 if the residuals don't fit in 32 bits.
+func riceParams(residuals []int64, size, order int) (params []int, partitionOrder, bits int) {
+	u := make([]uint64, len(residuals))
+	for i, r := range residuals {
+		if r < math.MinInt32 || r > math.MaxInt32 {
+			return nil, 0, -1
+		}
+		u[i] = uint64(r<<1) ^ uint64(r>>63)
+	}
+	bits = -1
+	for p := 0; p <= 8 && size%(1<<p) == 0 && size>>p > order; p++ {
+		partition := size >> p
+		candidate, total := make([]int, 1<<p), 6 // Coding method and partition order
+		for i := range candidate {
+			from, to := max(0, i*partition-order), (i+1)*partition-order
+			var sum uint64
+			for _, v := range u[from:to] {
+				sum += v
+			}
+			n := uint64(to - from)
+			// Parameter close to log2 of the mean, with the estimated size of the codes.
+			k := 0
+			for k < 30 && n<<(k+1) <= sum {
+				k++
+			}
+			candidate[i] = k
+			total += 5 + int(n)*(k+1) + int(sum>>k)
+		}
+		if bits < 0 || total < bits {
+			params, partitionOrder, bits = candidate, p, total
+		}
+	}
+	return params, partitionOrder, bits
+}
+
+// Writes bits, most significant first.
+type bitWriter struct {
+	b   []byte
+	acc uint64
+	n   int // Number of bits in acc
+}
+
+// Writes the lowest bits of v (up to 32 bits).
+func (w *bitWriter) write(v uint64, bits int) {
+	w.acc = w.acc<<bits | v&(1<<bits-1)
+	for w.n += bits; w.n >= 8; w.n -= 8 {
+		w.b = append(w.b, byte(w.acc>>(w.n-8)))
+	}
+}
+
+func (w *bitWriter) writeSigned(v int64, bits int) { w.write(uint64(v), bits) }
+
+// Writes v zeros followed by a one.
+func (w *bitWriter) unary(v uint64) {
+	for ; v > 32; v -= 32 {
+		w.write(0, 32)
+	}
+	w.write(1, int(v)+1)
+}
+
+// Pads with zeros to the next byte.
+func (w *bitWriter) align() {
+	if w.n > 0 {
+		w.write(0, 8-w.n)
+	}
+}
+
+// Appends a frame number coded like UTF-8 (extended to 36 bits).
+func appendFLACNumber(b []byte, v uint64) []byte {
+	if v < 0x80 {
+		return append(b, byte(v))
+	}
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	b = append(b, byte(0xFF<<(8-n))|byte(v>>(6*(n-1))))
+	for i := n - 2; i >= 0; i-- {
+		b = append(b, 0x80|byte(v>>(6*i))&0x3F)
+	}
+	return b
+}
+
+func crc8(b []byte) (crc byte) {
+	for _, v := range b {
+		crc ^= v
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func crc16(b []byte) (crc uint16) {
+	for _, v := range b {
+		crc ^= uint16(v) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,100 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FM synthesis operator: a sine oscillator whose phase can be modulated by other operators (like on a DX7).
+type Operator struct {
+	Ratio Signal // Frequency relative to the base frequency
+	Index Signal // Output level (this is the modulation index when the operator modulates another one)
+}
+
+// Describes how FM operators are connected (operators are referred to by their index).
+type Algorithm struct {
+	Modulators [][]int // Operators modulating each operator
+	Carriers   []int   // Operators that are heard (the output is their average)
+}
+
+// Common algorithms (for 2 to 4 operators).
+var (
+	FMPair     = Algorithm{Modulators: [][]int{{1}, nil}, Carriers: []int{0}}                    // 1 <- 2
+	FMStack    = Algorithm{Modulators: [][]int{{1}, {2}, {3}, nil}, Carriers: []int{0}}          // 1 <- 2 <- 3 <- 4
+	FMTwoPairs = Algorithm{Modulators: [][]int{{1}, nil, {3}, nil}, Carriers: []int{0, 2}}       // (1 <- 2) + (3 <- 4)
+	FMBranch   = Algorithm{Modulators: [][]int{{1, 2}, nil, nil}, Carriers: []int{0}}            // 1 <- (2 + 3)
+	FMAdditive = Algorithm{Modulators: [][]int{nil, nil, nil, nil}, Carriers: []int{0, 1, 2, 3}} // 1 + 2 + 3 + 4
+)
+
+// Returns the FM synthesis signal played at the given base frequency (silent if the algorithm has no carriers).
+// Panics if the algorithm refers to unknown operators or has a loop.
+//
+// Ex: bell-like tone (inharmonic modulator)
+//
+//	dsp.FM(music.A4, dsp.FMPair,
+//		dsp.Operator{Ratio: dsp.Constant(1), Index: dsp.Constant(1)},
+//		dsp.Operator{Ratio: dsp.Constant(3.5), Index: dsp.Amplify(dsp.Constant(4), decay)},
+//	)
+func FM(freq Signal, algo Algorithm, ops ...Operator) Signal {
+	order := algo.order(len(ops))
+	return SignalFunc(func(x time.Duration) (y float64) {
+		if len(algo.Carriers) == 0 {
+			return 0
+		}
+		f := freq.At(x)
+		var buf [8]float64 // Avoids allocating for common numbers of operators
+		out := buf[:]
+		if len(ops) > len(buf) {
+			out = make([]float64, len(ops))
+		}
+		for _, i := range order {
+			mod := 0.0
+			if i < len(algo.Modulators) {
+				for _, j := range algo.Modulators[i] {
+					mod += out[j]
+				}
+			}
+			op := ops[i]
+			out[i] = op.Index.At(x) * math.Sin(x.Seconds()*2*math.Pi*f*op.Ratio.At(x)+mod)
+		}
+		for _, i := range algo.Carriers {
+			y += out[i]
+		}
+		return y / float64(len(algo.Carriers))
+	})
+}
+
+// Returns the operators sorted so that modulators come before the operators they modulate.
+func (algo Algorithm) order(n int) (order []int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, n)
+	var visit func(i int)
+	visit = func(i int) {
+		if i < 0 || i >= n {
+			panic(fmt.Errorf("unknown FM operator: %d", i))
+		}
+		switch state[i] {
+		case visiting:
+			panic(fmt.Errorf("FM algorithm has a loop through operator %d", i))
+		case visited:
+			return
+		}
+		state[i] = visiting
+		if i < len(algo.Modulators) {
+			for _, j := range algo.Modulators[i] {
+				visit(j)
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+	}
+	for _, i := range algo.Carriers {
+		visit(i)
+	}
+	return order
+}
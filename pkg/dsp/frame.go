@@ -0,0 +1,26 @@
+package dsp
+
+import (
+	"time"
+)
+
+// Frames are sampled at exact integer positions (in nanoseconds) computed from their index,
+// so renders are sample-accurate and reproducible, however long they are.
+
+// Returns the position of the frame at index i.
+func FrameTime(i int64, rate int) time.Duration {
+	return time.Duration(i * int64(time.Second) / int64(rate))
+}
+
+// Returns the index of the frame closest to x.
+func FrameAt(x time.Duration, rate int) int64 {
+	return (int64(x)*int64(rate) + int64(time.Second)/2) / int64(time.Second)
+}
+
+// Returns the number of frames needed to cover d.
+func FrameCount(d time.Duration, rate int) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return (int64(d)*int64(rate) + int64(time.Second) - 1) / int64(time.Second)
+}
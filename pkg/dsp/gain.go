@@ -0,0 +1,56 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Returns the frames scaled so that their peak is at the given level (in dBFS).
+// Silent frames are returned unchanged.
+//
+// Ex: dsp.Normalize(frames, -1)
+func Normalize(frames []float64, targetPeak float64) []float64 {
+	peak := 0.0
+	for _, v := range frames {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	out := make([]float64, len(frames))
+	gain := 1.0
+	if peak > 0 {
+		gain = fromDB(targetPeak) / peak
+	}
+	for i, v := range frames {
+		out[i] = v * gain
+	}
+	return out
+}
+
+// Maximum gain applied by AutoGain (in dB), so that near-silent passages aren't boosted to full level.
+const AutoGainMax = 24
+
+// Gain rider: slowly adjusts the gain so that the level of the signal (RMS over the response time)
+// stays around the target (in dBFS), between -AutoGainMax and +AutoGainMax dB.
+// The gain goes down 10 times faster than it goes up, to limit overshoots when the signal gets louder.
+// The gain is kept as is while the signal is almost silent (below -60 dBFS).
+// Combine it with Limit to catch the peaks it's too slow to react to.
+//
+// Ex: dsp.Limit(dsp.AutoGain(mix, 44100, -18, time.Second), 44100, -1, 50*time.Millisecond)
+func AutoGain(in Signal, rate int, target float64, response time.Duration) Signal {
+	s, down := smoothing(response, rate), smoothing(response/10, rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		power, gain := 0.0, 1.0
+		return func(x time.Duration) (y float64) {
+			v := in.At(x)
+			power = s*power + (1-s)*v*v
+			if level := 10 * math.Log10(power); level > -60 {
+				want := fromDB(math.Max(-AutoGainMax, math.Min(AutoGainMax, target-level)))
+				if want < gain {
+					gain = down*gain + (1-down)*want
+				} else {
+					gain = s*gain + (1-s)*want
+				}
+			}
+			return v * gain
+		}
+	})
+}
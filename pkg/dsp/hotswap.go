@@ -0,0 +1,58 @@
+package dsp
+
+import (
+	"sync"
+	"time"
+)
+
+// Signal that can be replaced while it's being played, crossfading from the previous signal (for live coding).
+// Each signal starts playing from its beginning when it's swapped in
+// (rather than at the current position, which stateful signals would have to catch up on).
+// It's silent until the first swap, and is safe for concurrent use.
+//
+//	live := &dsp.HotSwap{Fade: 500 * time.Millisecond}
+//	live.Swap(dsp.Sine(dsp.Constant(440)))
+//	go playback.Play(ctx, live, time.Hour, playback.Options{})
+//	live.Swap(dsp.Sine(dsp.Constant(660))) // Crossfades from the current playback position
+type HotSwap struct {
+	Fade  time.Duration // Duration of the crossfade, defaults to 0 (immediate swap)
+	Curve FadeCurve     // Defaults to EqualPowerFade
+
+	mu                sync.Mutex
+	current, previous swapped
+	now               time.Duration // Last position played
+}
+
+type swapped struct {
+	signal Signal
+	start  time.Duration // Position at which the signal was swapped in
+}
+
+// Replaces the signal, crossfading from the last position played.
+// Swapping during a crossfade cuts the fade short (the outgoing signal is the one that was fading in).
+func (h *HotSwap) Swap(s Signal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.previous, h.current = h.current, swapped{s, h.now}
+}
+
+func (h *HotSwap) At(x time.Duration) (y float64) {
+	h.mu.Lock()
+	h.now = x
+	current, previous := h.current, h.previous
+	h.mu.Unlock()
+
+	t := x - current.start
+	if current.signal == nil || t < 0 {
+		return 0
+	}
+	if previous.signal == nil || t >= h.Fade {
+		return current.signal.At(t)
+	}
+	curve := h.Curve
+	if curve == nil {
+		curve = EqualPowerFade
+	}
+	out, in := curve(float64(t) / float64(h.Fade))
+	return out*previous.signal.At(x-previous.start) + in*current.signal.At(t)
+}
@@ -0,0 +1,44 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+)
+
+// Method used to read values between the frames of a buffer.
+type Interpolation int
+
+const (
+	LinearInterpolation Interpolation = iota
+	CubicInterpolation                // 4-point Hermite (Catmull-Rom)
+)
+
+// Returns the value of buf at the fractional index i.
+// When wrap is true, buf is treated as periodic, otherwise it's padded with zeros.
+func (interp Interpolation) At(buf []float64, i float64, wrap bool) float64 {
+	n := len(buf)
+	if n == 0 {
+		return 0
+	}
+	at := func(k int) float64 {
+		if wrap {
+			return buf[((k%n)+n)%n]
+		} else if k < 0 || k >= n {
+			return 0
+		}
+		return buf[k]
+	}
+	k := math.Floor(i)
+	i0, t := int(k), i-k
+	switch interp {
+	case LinearInterpolation:
+		return at(i0) + t*(at(i0+1)-at(i0))
+	case CubicInterpolation:
+		y0, y1, y2, y3 := at(i0-1), at(i0), at(i0+1), at(i0+2)
+		a := -y0/2 + 3*y1/2 - 3*y2/2 + y3/2
+		b := y0 - 5*y1/2 + 2*y2 - y3/2
+		c := -y0/2 + y2/2
+		return ((a*t+b)*t+c)*t + y1
+	}
+	panic(fmt.Errorf("unknown interpolation: %d", interp))
+}
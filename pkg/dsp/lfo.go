@@ -0,0 +1,77 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Low frequency oscillator, oscillates between offset-depth and offset+depth at the given rate (in Hertz).
+//
+// Ex: vibrato around A4 (± 5 Hz, 6 times per second):
+//
+//	dsp.Sine(dsp.LFO(dsp.Sine, dsp.Constant(6), dsp.Constant(5), music.A4))
+func LFO(shape Oscillator, rate, depth, offset Signal) Signal {
+	osc := shape(rate)
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return offset.At(x) + depth.At(x)*osc.At(x)
+	})
+}
+
+// Returns an oscillator that starts at the given phase (between 0 and 1) instead of 0.
+// This is useful to spread several LFOs across voices.
+func PhaseShift(shape Oscillator, phase float64) Oscillator {
+	return func(freq Signal) Signal {
+		osc := shape(freq)
+		return SignalFunc(func(x time.Duration) (y float64) {
+			return osc.At(x + time.Duration(phase*float64(time.Second)/freq.At(x)))
+		})
+	}
+}
+
+// Sample and hold: outputs the value of the source at the last rising edge of the clock
+// (when the clock goes from 0 or below to above 0), the source is first sampled at the start.
+//
+// Ex: random filter cutoff (between 400 and 2000 Hz) changing 8 times per second
+//
+//	random := dsp.SampleHold(dsp.Noise(1), dsp.Square(dsp.Constant(8)), rate)
+//	cutoff := dsp.SignalFunc(func(x time.Duration) float64 { return 1200 + 800*random.At(x) })
+func SampleHold(source, clock Signal, rate int) Signal {
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var held, last float64
+		first := true
+		return func(x time.Duration) (y float64) {
+			c := clock.At(x)
+			if first || (last <= 0 && c > 0) {
+				held, first = source.At(x), false
+			}
+			last = c
+			return held
+		}
+	})
+}
+
+// Slew limiter: follows the input, but changes by at most rise (going up) or fall (going down) units per second.
+// It smooths abrupt parameter changes (ex: a sequenced cutoff or pitch) to avoid clicks, or to glide between notes.
+//
+// Ex: portamento, gliding up an octave from A4 in 100ms (and back down in 200ms)
+//
+//	dsp.Sine(dsp.Slew(melody, rate, 4400, 2200))
+func Slew(in Signal, rate int, rise, fall float64) Signal {
+	up, down := rise/float64(rate), fall/float64(rate)
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		var y float64
+		first := true
+		return func(x time.Duration) float64 {
+			v := in.At(x)
+			switch {
+			case first:
+				y, first = v, false
+			case v > y:
+				y = math.Min(v, y+up)
+			default:
+				y = math.Max(v, y-down)
+			}
+			return y
+		}
+	})
+}
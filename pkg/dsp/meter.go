@@ -0,0 +1,102 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Loudness measurements (in dB, -Inf for silence).
+type Loudness struct {
+	Peak     float64 // Highest absolute frame value (dBFS)
+	TruePeak float64 // Highest value between frames, estimated by oversampling 4 times (dBTP)
+	RMS      float64 // Root mean square (dBFS)
+	LUFS     float64 // Integrated loudness (ITU-R BS.1770 / EBU R128), K-weighted and gated
+}
+
+// Measures the loudness of frames (interleaved when channels > 1).
+func Measure(frames []float64, rate, channels int) (l Loudness) {
+	channels = max(channels, 1)
+	split := make([][]float64, channels)
+	for c := range split {
+		split[c] = make([]float64, 0, len(frames)/channels)
+	}
+	peak, truePeak, sum := 0.0, 0.0, 0.0
+	for i, v := range frames {
+		split[i%channels] = append(split[i%channels], v)
+		peak = math.Max(peak, math.Abs(v))
+		sum += v * v
+	}
+	for _, ch := range split {
+		for _, v := range Resample(ch, rate, 4*rate) {
+			truePeak = math.Max(truePeak, math.Abs(v))
+		}
+	}
+	return Loudness{
+		Peak:     toDB(peak),
+		TruePeak: toDB(math.Max(peak, truePeak)),
+		RMS:      toDB(math.Sqrt(sum / float64(max(len(frames), 1)))),
+		LUFS:     integratedLoudness(split, rate),
+	}
+}
+
+// Measures the loudness of a signal from 0 to d.
+func MeasureSignal(s Signal, rate int, d time.Duration) Loudness {
+	return Measure(Sample(s, rate, 0, d), rate, 1)
+}
+
+// Returns the gated loudness (in LUFS) of the channels: the mean power of 400ms blocks (overlapping by 75%),
+// ignoring blocks below -70 LUFS and then blocks 10 LU below the loudness of the remaining ones.
+func integratedLoudness(channels [][]float64, rate int) float64 {
+	// K-weighting: high shelf (head) followed by a high-pass filter, coefficients for any rate from libebur128.
+	shelf := func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		k := math.Tan(math.Pi * 1681.974450955533 / float64(rate))
+		q := 0.7071752369554196
+		vh := math.Pow(10, 3.999843853973347/20)
+		vb := math.Pow(vh, 0.4996667741545416)
+		return vh + vb*k/q + k*k, 2 * (k*k - vh), vh - vb*k/q + k*k, 1 + k/q + k*k, 2 * (k*k - 1), 1 - k/q + k*k
+	}
+	highPass := func(x time.Duration) (b0, b1, b2, a0, a1, a2 float64) {
+		k := math.Tan(math.Pi * 38.13547087602444 / float64(rate))
+		q := 0.5003270373238773
+		return 1, -2, 1, 1 + k/q + k*k, 2 * (k*k - 1), 1 - k/q + k*k
+	}
+
+	n := 0
+	weighted := make([][]float64, len(channels))
+	for c, ch := range channels {
+		s := biquad(biquad(Buffer{Frames: ch, Rate: rate}, rate, shelf), rate, highPass)
+		weighted[c] = make([]float64, len(ch))
+		for i := range ch {
+			weighted[c][i] = s.At(FrameTime(int64(i), rate))
+		}
+		n = len(ch)
+	}
+
+	block, step := int(0.4*float64(rate)), int(0.1*float64(rate))
+	var powers []float64
+	for start := 0; start+block <= n; start += step {
+		p := 0.0
+		for _, ch := range weighted {
+			for _, v := range ch[start : start+block] {
+				p += v * v
+			}
+		}
+		powers = append(powers, p/float64(block))
+	}
+	lufs := func(p float64) float64 { return -0.691 + 10*math.Log10(p) }
+	gated := func(threshold float64) (mean float64) {
+		count := 0
+		for _, p := range powers {
+			if lufs(p) > threshold {
+				mean += p
+				count++
+			}
+		}
+		if count == 0 {
+			return 0
+		}
+		return mean / float64(count)
+	}
+	relative := lufs(gated(-70)) - 10
+	return lufs(gated(math.Max(-70, relative)))
+}
@@ -0,0 +1,114 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Mixes tracks into a stereo master: each track has its own gain and position in the stereo field,
+// and can send some of its signal to buses (to share effects, ex: a single reverb for all tracks).
+// Unlike Combine, signals are summed (not averaged), so adding a track doesn't change the level of the others.
+//
+//	mix := dsp.Mixer{
+//		Tracks: []dsp.Track{
+//			{Name: "drums", In: drums, Gain: -3},
+//			{Name: "pad", In: pad, Gain: -9, Pan: -0.3, Sends: map[string]float64{"reverb": -6}},
+//		},
+//		Buses: []dsp.Bus{
+//			{Name: "reverb", Effect: func(in dsp.Signal) dsp.Signal { return dsp.Reverb(in, rate, 0.8, 0.5, 1) }},
+//		},
+//	}
+//	master := mix.Master()
+type Mixer struct {
+	Tracks []Track
+	Buses  []Bus
+	Gain   float64 // Master gain (in dB)
+}
+
+type Track struct {
+	Name       string
+	In         Signal
+	Gain       float64            // In dB
+	Pan        float64            // From -1 (left) to 1 (right)
+	Sends      map[string]float64 // Level sent to each bus, by name (in dB, after the track gain)
+	Mute, Solo bool               // When some tracks are soloed, only they are heard
+}
+
+// Effect (or sub-mix) shared by tracks, its output is mixed into the master.
+type Bus struct {
+	Name   string
+	Effect func(in Signal) Signal // Processes the sum of the signals sent to the bus (with a fully wet mix), nil for a plain sub-mix
+	Gain   float64                // In dB
+	Pan    float64                // From -1 (left) to 1 (right)
+}
+
+// Returns the mix. It panics if a track sends to an unknown bus.
+func (m Mixer) Master() Stereo {
+	solo := false
+	for _, t := range m.Tracks {
+		solo = solo || t.Solo
+	}
+
+	var channels []mixerChannel
+	sends := make(map[string][]mixerChannel, len(m.Buses))
+	for _, b := range m.Buses {
+		sends[b.Name] = nil
+	}
+	for _, t := range m.Tracks {
+		for name := range t.Sends {
+			if _, ok := sends[name]; !ok {
+				panic(fmt.Errorf("track %q sends to unknown mixer bus %q", t.Name, name))
+			}
+		}
+		if t.Mute || (solo && !t.Solo) {
+			continue
+		}
+		gain := fromDB(t.Gain)
+		channels = append(channels, newMixerChannel(t.In, gain, t.Pan))
+		for name, level := range t.Sends {
+			sends[name] = append(sends[name], mixerChannel{in: t.In, left: gain * fromDB(level)})
+		}
+	}
+	for _, b := range m.Buses {
+		in := sends[b.Name]
+		bus := SignalFunc(func(x time.Duration) (y float64) {
+			for _, c := range in {
+				y += c.left * c.in.At(x)
+			}
+			return y
+		})
+		out := Signal(bus)
+		if b.Effect != nil {
+			out = b.Effect(bus)
+		}
+		channels = append(channels, newMixerChannel(out, fromDB(b.Gain), b.Pan))
+	}
+
+	master := fromDB(m.Gain)
+	return Stereo{
+		Left: SignalFunc(func(x time.Duration) (y float64) {
+			for _, c := range channels {
+				y += c.left * c.in.At(x)
+			}
+			return master * y
+		}),
+		Right: SignalFunc(func(x time.Duration) (y float64) {
+			for _, c := range channels {
+				y += c.right * c.in.At(x)
+			}
+			return master * y
+		}),
+	}
+}
+
+// Signal with the gain applied to each side (like Pan, with a constant position).
+type mixerChannel struct {
+	in          Signal
+	left, right float64
+}
+
+func newMixerChannel(in Signal, gain, pan float64) mixerChannel {
+	angle := (math.Max(-1, math.Min(1, pan)) + 1) * math.Pi / 4
+	return mixerChannel{in: in, left: gain * math.Cos(angle), right: gain * math.Sin(angle)}
+}
@@ -0,0 +1,92 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Ring modulation: multiplies two (bipolar) signals together.
+// The result contains the sum and difference of their frequencies (and neither of the originals),
+// which gives metallic and bell-like tones.
+//
+// Ex: dsp.RingMod(dsp.Sine(music.A4), dsp.Sine(dsp.Constant(330)))
+func RingMod(a, b Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return a.At(x) * b.At(x)
+	})
+}
+
+// Amplitude modulation: the gain of the carrier follows the modulator (between -1 and 1).
+// The depth (between 0 and 1) sets how much the gain varies: with depth 1, the gain goes from 0 to 1,
+// with depth 0, the carrier is unchanged.
+//
+// Ex: tremolo (6 times per second)
+//
+//	dsp.AM(dsp.Saw(music.A4), dsp.Sine(dsp.Constant(6)), dsp.Constant(0.5))
+func AM(carrier, modulator, depth Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		d := depth.At(x)
+		return carrier.At(x) * (1 - d/2 + d/2*modulator.At(x))
+	})
+}
+
+// Periodic variation of volume, speed times per second (in Hertz).
+// The depth (between 0 and 1) is the same as for AM: with depth 1, the volume goes down to silence.
+func Tremolo(in Signal, speed, depth float64) Signal {
+	return AM(in, Sine(Constant(speed)), Constant(depth))
+}
+
+// Periodic variation of pitch (up to ± depthCents), speed times per second (in Hertz).
+// Works on any signal (not only oscillators) by reading the input slightly behind (never ahead),
+// the input is still read in order, so it can be stateful.
+//
+// Ex: dsp.Vibrato(voice, 6, 20)
+func Vibrato(in Signal, speed, depthCents float64) Signal {
+	// Reading the input at x - d(x) plays it at speed 1 - d'(x),
+	// with d(x) = a·(1 - cos(ωx)), the pitch ratio goes from 1 - aω to 1 + aω.
+	w := 2 * math.Pi * speed
+	a := (math.Pow(2, depthCents/1200) - 1) / w
+	return SignalFunc(func(x time.Duration) (y float64) {
+		d := a * (1 - math.Cos(w*x.Seconds()))
+		return in.At(x - time.Duration(d*float64(time.Second)))
+	})
+}
+
+// Rate at which speed curves are sampled (in Hertz).
+const speedRate = 1000
+
+// Plays the input faster or slower following the curve (varispeed, like a tape machine): 1 is the normal speed,
+// 2 is twice as fast (an octave higher), 0 stops it, negative values play it backwards.
+// Like Vibrato, it works on any signal read in order (as long as the speed stays positive).
+// The curve is sampled every millisecond, which is enough for slides and tape effects (but not for FM).
+//
+// Ex: a tape stop over half a second, or a clip played 10% faster to match a tempo
+//
+//	dsp.Speed(mix, dsp.SignalFunc(func(x time.Duration) float64 { return max(1-x.Seconds()/0.5, 0) }))
+//	dsp.F(time.Duration(float64(clip.Duration)/1.1), dsp.Speed(clip, dsp.Constant(1.1)))
+func Speed(in, factor Signal) Signal {
+	// Position in the input (in seconds) at each frame of the curve.
+	position := Stateful(speedRate, func() func(x time.Duration) float64 {
+		var pos float64
+		return func(x time.Duration) (y float64) {
+			if x > 0 {
+				pos += factor.At(x) / speedRate
+			}
+			return pos
+		}
+	})
+	return SignalFunc(func(x time.Duration) (y float64) {
+		pos := position.At(x) + (x-FrameTime(FrameAt(x, speedRate), speedRate)).Seconds()*factor.At(x)
+		return in.At(time.Duration(pos * float64(time.Second)))
+	})
+}
+
+// Bends the pitch of the input by a number of semitones following the curve (ex: 0.5 for 50 cents, -12 for an octave down),
+// by playing the input faster or slower (see Speed).
+//
+// Ex: sliding up a whole tone over half a second
+//
+//	dsp.Bend(voice, dsp.SignalFunc(func(x time.Duration) float64 { return 2 * min(x.Seconds()/0.5, 1) }))
+func Bend(in, semitones Signal) Signal {
+	return Speed(in, SignalFunc(func(x time.Duration) (y float64) { return math.Pow(2, semitones.At(x)/12) }))
+}
@@ -0,0 +1,18 @@
+package dsp
+
+import (
+	"time"
+)
+
+// White noise between -1 and 1.
+// Values are derived from x (and the seed), so the noise is the same every time it's sampled.
+func Noise(seed uint64) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		// SplitMix64 finalizer.
+		z := uint64(x) + seed*0x9e3779b97f4a7c15
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		return float64(z>>11)/(1<<52) - 1
+	})
+}
@@ -0,0 +1,102 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp/fft"
+)
+
+// Size of the windows (and distance between them) used to detect onsets, at 44.1kHz.
+const (
+	onsetWindow = 1024
+	onsetHop    = 256
+)
+
+// Returns when notes or hits start in the frames (onsets), using spectral flux:
+// an onset is a sudden rise of energy across the spectrum, compared to the recent average.
+// Sensitivity goes from 0 (only the strongest onsets) to 1 (every small change), 0.5 is a good start.
+func Onsets(frames []float64, rate int, sensitivity float64) (onsets []time.Duration) {
+	flux, hop, size := spectralFlux(frames, rate)
+	const radius = 8 // Windows around each one used for the adaptive threshold
+	loudest := 0.0
+	for _, v := range flux {
+		loudest = math.Max(loudest, v)
+	}
+	delta := (1 - sensitivity) * 0.3 * loudest
+	last := -radius
+	for i, v := range flux {
+		lo, hi := max(0, i-radius), min(len(flux), i+radius+1)
+		local := flux[lo:hi]
+		isPeak := true
+		for _, w := range local {
+			if w > v {
+				isPeak = false
+				break
+			}
+		}
+		if isPeak && v > 0 && v >= mean(local)+delta && i-last >= radius/2 {
+			// The energy rise happens at the end of the window.
+			onsets = append(onsets, FrameTime(int64(i*hop+size-hop), rate))
+			last = i
+		}
+	}
+	return onsets
+}
+
+// Estimates the tempo of the frames (in beats per minute, between 60 and 200),
+// from the periodicity of their onset strength (autocorrelation of the spectral flux).
+//
+// Ex: conforming a loop to the project tempo: dsp.TimeStretch(loop, dsp.DetectBPM(loop.Frames, loop.Rate)/float64(bpm))
+func DetectBPM(frames []float64, rate int) float64 {
+	flux, hop, _ := spectralFlux(frames, rate)
+	m := mean(flux)
+	for i := range flux {
+		flux[i] -= m
+	}
+	windowsPerMinute := 60 * float64(rate) / float64(hop)
+	best, bestScore := 0.0, math.Inf(-1)
+	for lag := int(windowsPerMinute / 200); lag <= int(windowsPerMinute/60) && lag < len(flux); lag++ {
+		score := 0.0
+		for i := lag; i < len(flux); i++ {
+			score += flux[i] * flux[i-lag]
+		}
+		score /= float64(len(flux) - lag)
+		// Halving or doubling the tempo often fits as well, so tempos close to 120 BPM are preferred.
+		score *= math.Exp(-0.5 * math.Pow(math.Log2(windowsPerMinute/float64(lag)/120), 2))
+		if score > bestScore {
+			best, bestScore = float64(lag), score
+		}
+	}
+	if best == 0 {
+		return 0
+	}
+	return windowsPerMinute / best
+}
+
+// Returns the positive change of (log) magnitudes between successive spectra,
+// the number of frames between them and the size of each window.
+func spectralFlux(frames []float64, rate int) (flux []float64, hop, size int) {
+	scale := float64(rate) / 44100
+	size = fft.Size(int(onsetWindow * scale))
+	hop = max(1, int(onsetHop*scale))
+	spectra := fft.STFT(frames, size, hop, fft.Hann)
+	flux = make([]float64, len(spectra))
+	prev := make([]float64, size/2+1)
+	for i, spectrum := range spectra {
+		for k, c := range spectrum {
+			mag := math.Log1p(100 * cmplx.Abs(c))
+			flux[i] += math.Max(0, mag-prev[k])
+			prev[k] = mag
+		}
+	}
+	return flux, hop, size
+}
+
+func mean(values []float64) (m float64) {
+	for _, v := range values {
+		m += v
+	}
+	return m / float64(max(len(values), 1))
+}
@@ -0,0 +1,137 @@
+package dsp
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Constructor for a periodic signal oscillating at the given frequency (in Hertz).
+// Ex: Sine, Square, Saw, Triangle, BandLimited(44100).Saw, etc.
+type Oscillator func(freq Signal) Signal
+
+func Sine(freq Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return math.Sin(x.Seconds() * 2 * math.Pi * freq.At(x))
+	})
+}
+
+func Square(freq Signal) Signal { return Pulse(freq, Constant(0.5)) }
+
+// Pulse wave oscillator, the duty cycle (between 0 and 1) is the proportion of each period spent at 1.
+func Pulse(freq, duty Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		if phase(x, freq.At(x)) < duty.At(x) {
+			return 1
+		}
+		return -1
+	})
+}
+
+// Returns the position (between 0 and 1) within the current period of an oscillator.
+func phase(x time.Duration, freq float64) float64 {
+	return frac(x.Seconds() * freq)
+}
+
+func frac(v float64) float64 { return v - math.Floor(v) }
+
+// Rising sawtooth wave oscillator (starts at 0, like Sine).
+func Saw(freq Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return 2*frac(phase(x, freq.At(x))+0.5) - 1
+	})
+}
+
+// Triangle wave oscillator (starts at 0 and rises first, like Sine).
+func Triangle(freq Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		return 1 - 4*math.Abs(frac(phase(x, freq.At(x))+0.25)-0.5)
+	})
+}
+
+// Band-limited oscillators (using PolyBLEP) for a given sample rate.
+// They sound less harsh than their naive counterparts at high frequencies
+// since they greatly reduce aliasing.
+//
+// Ex: dsp.BandLimited(44100).Saw(music.A4)
+type BandLimited int
+
+func (r BandLimited) Saw(freq Signal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		f := freq.At(x)
+		t := frac(phase(x, f) + 0.5)
+		return 2*t - 1 - polyBLEP(t, f/float64(r))
+	})
+}
+
+func (r BandLimited) Square(freq Signal) Signal { return r.Pulse(freq, Constant(0.5)) }
+
+func (r BandLimited) Pulse(freq, duty Signal) Signal {
+	naive := Pulse(freq, duty)
+	return SignalFunc(func(x time.Duration) (y float64) {
+		f, d := freq.At(x), duty.At(x)
+		t, dt := phase(x, f), f/float64(r)
+		return naive.At(x) + polyBLEP(t, dt) - polyBLEP(frac(t-d), dt)
+	})
+}
+
+func (r BandLimited) Triangle(freq Signal) Signal {
+	naive := Triangle(freq)
+	return SignalFunc(func(x time.Duration) (y float64) {
+		f := freq.At(x)
+		t, dt := frac(phase(x, f)+0.25), f/float64(r)
+		return naive.At(x) + 4*dt*(polyBLAMP(t, dt)-polyBLAMP(frac(t+0.5), dt))
+	})
+}
+
+// Returns the correction to apply around a discontinuity of height 2 (located at t = 0),
+// where t is the oscillator phase and dt the phase increment per frame.
+func polyBLEP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t /= dt
+		return t + t - t*t - 1
+	case t > 1-dt:
+		t = (t - 1) / dt
+		return t*t + t + t + 1
+	}
+	return 0
+}
+
+// Like polyBLEP, but for a discontinuity in the first derivative (ex: the corners of a triangle wave).
+func polyBLAMP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		t = t/dt - 1
+		return -t * t * t / 3
+	case t > 1-dt:
+		t = (t-1)/dt + 1
+		return t * t * t / 3
+	}
+	return 0
+}
+
+// Oscillator playing a single period of a waveform defined by the given table (using linear interpolation).
+func Wavetable(table []float64, freq Signal) Signal {
+	return Wavetables([][]float64{table}, Constant(0), freq, LinearInterpolation)
+}
+
+// Like Wavetable, but morphs between several tables,
+// morph goes from 0 (first table) to 1 (last table).
+// The oscillator is silent without tables, or if one of them is empty.
+func Wavetables(tables [][]float64, morph, freq Signal, interp Interpolation) Signal {
+	if len(tables) == 0 || slices.ContainsFunc(tables, func(t []float64) bool { return len(t) == 0 }) {
+		return Constant(0)
+	}
+	return SignalFunc(func(x time.Duration) (y float64) {
+		p := phase(x, freq.At(x))
+		m := math.Max(0, math.Min(1, morph.At(x))) * float64(len(tables)-1)
+		i := int(m)
+		y = interp.At(tables[i], p*float64(len(tables[i])), true)
+		if i+1 < len(tables) {
+			next := interp.At(tables[i+1], p*float64(len(tables[i+1])), true)
+			y += (m - float64(i)) * (next - y)
+		}
+		return y
+	})
+}
@@ -0,0 +1,71 @@
+package dsp
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Like Sample, but splits the range in chunks rendered concurrently by several workers (defaults to GOMAXPROCS),
+// each worker rendering its own instance of the signal (returned by build).
+//
+// Only signals marked as pure (see Pure) are rendered in parallel, others are rendered serially,
+// since stateful signals (see Stateful) compute each of their frames from the previous ones.
+func SampleParallel(build func() Signal, rate int, from, to time.Duration, workers int) (frames []float64) {
+	s := build()
+	if !IsPure(s) {
+		return Sample(s, rate, from, to)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	frames = make([]float64, FrameCount(to, rate))
+	chunkSize := max(len(frames)/(4*workers), BlockSize)
+	chunks := make(chan int)
+	go func() {
+		defer close(chunks)
+		for i := 0; i < len(frames); i += chunkSize {
+			chunks <- i
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		if w > 0 {
+			s = build()
+		}
+		wg.Add(1)
+		go func(s Signal) {
+			defer wg.Done()
+			for start := range chunks {
+				for i := start; i < min(start+chunkSize, len(frames)); i++ {
+					frames[i] = s.At(from + FrameTime(int64(i), rate))
+				}
+			}
+		}(s)
+	}
+	wg.Wait()
+	return frames
+}
+
+// Marks a signal as pure: its value at x only depends on x, and it's safe for concurrent use,
+// so it can be rendered in parallel (see SampleParallel).
+// It's up to the caller to make sure the signal contains no stateful signal (filters, delays, synths, Freeze, ...),
+// including signals captured from outside of the function building it.
+//
+// Ex: dsp.SampleParallel(func() dsp.Signal { return dsp.Pure(dsp.Sine(dsp.Constant(440))) }, 44100, 0, time.Minute, 0)
+func Pure(s Signal) Signal { return pure{s} }
+
+type pure struct{ Signal }
+
+func (pure) isPure() {}
+
+// Reports whether the signal is known to be pure (see Pure): marked with Pure, or a constant or a buffer.
+func IsPure(s Signal) bool {
+	switch s.(type) {
+	case interface{ isPure() }, Buffer, constant:
+		return true
+	}
+	return false
+}
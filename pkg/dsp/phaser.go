@@ -0,0 +1,37 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Phaser: the signal goes through a chain of allpass filters (stages) centered around a frequency (in Hertz),
+// and is mixed with the dry signal, which creates notches in the spectrum (one for every 2 stages).
+// Modulating the center frequency sweeps the notches, feedback (between -1 and 1) makes them more pronounced,
+// and a mix of 0.5 gives the deepest notches.
+//
+// Ex: classic 4-stage phaser sweeping between 200 Hz and 1800 Hz every 2 seconds
+//
+//	dsp.Phaser(in, rate, dsp.LFO(dsp.Sine, dsp.Constant(0.5), dsp.Constant(800), dsp.Constant(1000)), 4, 0.5, 0.5)
+func Phaser(in Signal, rate int, center Signal, stages int, feedback, mix float64) Signal {
+	feedback = math.Max(-0.95, math.Min(0.95, feedback))
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		x1, y1 := make([]float64, stages), make([]float64, stages)
+		var last float64
+		return func(x time.Duration) (y float64) {
+			f := math.Max(1, math.Min(0.49*float64(rate), center.At(x)))
+			t := math.Tan(math.Pi * f / float64(rate))
+			a := (t - 1) / (t + 1)
+
+			dry := in.At(x)
+			v := dry + feedback*last
+			for i := range stages {
+				out := a*v + x1[i] - a*y1[i]
+				x1[i], y1[i] = v, out
+				v = out
+			}
+			last = v
+			return (1-mix)*dry + mix*v
+		}
+	})
+}
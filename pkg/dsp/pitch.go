@@ -0,0 +1,96 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Frequency range searched by DetectPitch (in Hertz).
+const (
+	MinPitch = 40
+	MaxPitch = 4000
+)
+
+// Estimates the fundamental frequency of the frames (in Hertz) using the YIN algorithm.
+// The clarity (from 0 to 1) tells how periodic the frames are: it's close to 1 for clean tones,
+// and low for noise (in which case the frequency isn't meaningful).
+// Frames should cover at least two periods of the lowest expected frequency (ex: 2048 frames at 44.1kHz).
+func DetectPitch(frames []float64, rate int) (freq, clarity float64) {
+	const threshold = 0.1
+	minLag := max(2, int(float64(rate)/MaxPitch))
+	maxLag := min(len(frames)/2, int(float64(rate)/MinPitch))
+	if maxLag <= minLag {
+		return 0, 0
+	}
+
+	// Cumulative mean normalized difference between the frames and the frames delayed by each lag.
+	diff := make([]float64, maxLag+1)
+	diff[0] = 1
+	sum := 0.0
+	for lag := 1; lag <= maxLag; lag++ {
+		d := 0.0
+		for i := range len(frames) - maxLag {
+			delta := frames[i] - frames[i+lag]
+			d += delta * delta
+		}
+		sum += d
+		diff[lag] = 1
+		if sum > 0 {
+			diff[lag] = d * float64(lag) / sum
+		}
+	}
+
+	// Pick the first dip below the threshold (or the lowest one if there is none).
+	best := minLag
+	for lag := minLag; lag <= maxLag; lag++ {
+		if diff[lag] < threshold {
+			for lag+1 <= maxLag && diff[lag+1] < diff[lag] {
+				lag++
+			}
+			best = lag
+			break
+		}
+		if diff[lag] < diff[best] {
+			best = lag
+		}
+	}
+
+	// Refine the lag between frames (parabolic interpolation).
+	lag := float64(best)
+	if best > minLag && best < maxLag {
+		a, b, c := diff[best-1], diff[best], diff[best+1]
+		if den := a - 2*b + c; den != 0 {
+			lag += (a - c) / (2 * den)
+		}
+	}
+	return float64(rate) / lag, math.Max(0, 1-diff[best])
+}
+
+// Returns the fundamental frequency of the input (see DetectPitch) over the last window, updated 4 times per window.
+// The signal is 0 until the first window is complete and when the input isn't clearly pitched.
+//
+// Ex: tuner: music.NearestNote(dsp.Pitch(mic, 44100, 50*time.Millisecond).At(x))
+func Pitch(in Signal, rate int, window time.Duration) Signal {
+	size := max(int(window.Seconds()*float64(rate)), 4)
+	hop := size / 4
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		line := newDelayLine(size)
+		frames := make([]float64, size)
+		var n int
+		var freq float64
+		return func(x time.Duration) (y float64) {
+			line.write(in.At(x))
+			if n++; n >= size && n%hop == 0 {
+				for i := range frames {
+					frames[i] = line.buf[(line.pos+i)%size]
+				}
+				f, clarity := DetectPitch(frames, rate)
+				freq = 0
+				if clarity > 0.5 {
+					freq = f
+				}
+			}
+			return freq
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package dsp
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Plucked string (Karplus-Strong): a burst of noise circulates in a feedback delay line
+// (one period of the given frequency long) and is smoothed by a damping filter on each round trip.
+// The decay is the time it takes for the string to fade out (by 60dB).
+//
+// Ex: dsp.Pluck(music.A4.Hz(), 44100, 2*time.Second)
+func Pluck(freq float64, rate int, decay time.Duration) Signal {
+	period := float64(rate) / freq
+	gain := math.Pow(10, -3/(freq*decay.Seconds()))
+	burst := int64(math.Ceil(period))
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		noise := rand.New(rand.NewPCG(uint64(rate), math.Float64bits(freq)))
+		line := newDelayLine(int(period) + 3)
+		var frame int64
+		return func(x time.Duration) (y float64) {
+			// The two-point average delays by half a frame, so the loop lasts exactly one period.
+			y = gain * (line.read(period-0.5) + line.read(period+0.5)) / 2
+			if frame < burst {
+				y += 2*noise.Float64() - 1
+			}
+			frame++
+			line.write(y)
+			return y
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package dsp
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// Signal playing raw PCM read from an io.Reader as it's sampled (ex: os.Stdin, a network connection),
+// so signals can process audio coming from other programs:
+//
+//	in := &dsp.PCMReader{Reader: os.Stdin, Rate: 44100, Options: dsp.EncodeOptions{Format: dsp.Int16}}
+//	out := dsp.Reverb(in, 44100, 0.8, 0.5, 0.3)
+//
+// Frames are read when positions past the last frame read are sampled,
+// and the most recent frames are kept for signals reading their input slightly behind (see History).
+// Positions before the frames kept, or after the end of the input, are silent.
+//
+// It is not safe for concurrent use.
+type PCMReader struct {
+	Reader   io.Reader
+	Rate     int
+	Channels int           // Number of interleaved channels, mixed down to mono, defaults to 1
+	Options  EncodeOptions // Format and byte order of the frames
+	History  time.Duration // How long frames are kept after being read, defaults to 1s
+
+	r      *bufio.Reader
+	frame  []byte    // Encoded frame being decoded
+	frames []float64 // Ring buffer of the last frames read
+	read   int64     // Number of frames read
+	err    error
+}
+
+func (p *PCMReader) At(x time.Duration) (y float64) {
+	if p.r == nil {
+		p.init()
+	}
+	n := FrameAt(x, p.Rate)
+	for n >= p.read && p.err == nil {
+		p.next()
+	}
+	if n < 0 || n >= p.read || n < p.read-int64(len(p.frames)) {
+		return 0
+	}
+	return p.frames[n%int64(len(p.frames))]
+}
+
+func (p *PCMReader) init() {
+	history := p.History
+	if history == 0 {
+		history = time.Second
+	}
+	p.r = bufio.NewReader(p.Reader)
+	p.frame = make([]byte, max(p.Channels, 1)*p.Options.Format.Size())
+	p.frames = make([]float64, max(FrameCount(history, p.Rate), 1))
+}
+
+// Reads and decodes the next frame.
+func (p *PCMReader) next() {
+	if _, err := io.ReadFull(p.r, p.frame); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF // The last frame is incomplete
+		}
+		p.err = err
+		return
+	}
+	decoded := DecodePCM(p.frame, p.Options)
+	var y float64
+	for _, v := range decoded {
+		y += v
+	}
+	p.frames[p.read%int64(len(p.frames))] = y / float64(len(decoded))
+	p.read++
+}
+
+// Returns the duration of the frames read so far.
+func (p *PCMReader) Duration() time.Duration { return FrameTime(p.read, p.Rate) }
+
+// Returns the error that stopped reading (io.EOF at the end of the input), or nil while frames can still be read.
+func (p *PCMReader) Err() error { return p.err }
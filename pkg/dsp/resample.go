@@ -0,0 +1,108 @@
+package dsp
+
+import (
+	"math"
+)
+
+// Number of zero crossings of the resampling filter on each side of a frame.
+// More zero crossings give a steeper filter (less aliasing and a flatter passband) but cost more.
+const ResampleZeroCrossings = 32
+
+// Maximum number of phases for which the coefficients of the resampling filter are computed.
+const resamplePhases = 512
+
+// Converts frames sampled at a given rate to another rate, using a windowed-sinc (Blackman) filter.
+// When converting to a lower rate, frequencies above the new Nyquist frequency are filtered out first,
+// so they don't alias.
+//
+// Ex: 48kHz to 44.1kHz: dsp.Resample(frames, 48000, 44100)
+func Resample(frames []float64, from, to int) (out []float64) {
+	if from == to {
+		return append([]float64(nil), frames...)
+	}
+	// Output frame j is at input position j*m/l.
+	g := gcd(from, to)
+	l, m := to/g, from/g
+	cutoff := 0.9 * math.Min(1, float64(to)/float64(from)) // Relative to the input's Nyquist frequency
+	half := int(math.Ceil(ResampleZeroCrossings / cutoff))
+
+	// Coefficients only depend on the fractional part of the input position (one of l phases).
+	kernel := func(frac float64) []float64 {
+		taps := make([]float64, 2*half)
+		sum := 0.0
+		for k := range taps {
+			d := float64(half-1-k) + frac
+			taps[k] = cutoff * sinc(cutoff*d) * blackman(d/float64(half))
+			sum += taps[k]
+		}
+		for k := range taps {
+			taps[k] /= sum // Unity gain at 0Hz
+		}
+		return taps
+	}
+
+	// With many phases (ex: when pitch shifting), kernels are computed for a fixed number of phases,
+	// and interpolated between the two closest ones.
+	phases := l
+	if l > resamplePhases {
+		phases = resamplePhases
+	}
+	table := make([][]float64, phases+1)
+	taps := make([]float64, 2*half)
+
+	out = make([]float64, (int64(len(frames))*int64(l)+int64(m)-1)/int64(m))
+	for j := range out {
+		pos := int64(j) * int64(m)
+		n := int(pos / int64(l))
+		p, t := int(pos%int64(l)), 0.0
+		if phases != l {
+			v := float64(pos%int64(l)) / float64(l) * float64(phases)
+			p, t = int(v), v-math.Floor(v)
+		}
+		if table[p] == nil {
+			table[p] = kernel(float64(p) / float64(phases))
+		}
+		copy(taps, table[p])
+		if t > 0 {
+			if table[p+1] == nil {
+				table[p+1] = kernel(float64(p+1) / float64(phases))
+			}
+			for k, c := range table[p+1] {
+				taps[k] += t * (c - taps[k])
+			}
+		}
+		for k, c := range taps {
+			if i := n - half + 1 + k; i >= 0 && i < len(frames) {
+				out[j] += c * frames[i]
+			}
+		}
+	}
+	return out
+}
+
+// Returns the buffer converted to the given sample rate (see Resample).
+func (b Buffer) Resample(rate int) Buffer {
+	return Buffer{Frames: Resample(b.Frames, b.Rate, rate), Rate: rate}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// Blackman window, x goes from -1 to 1.
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
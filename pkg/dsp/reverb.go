@@ -0,0 +1,50 @@
+package dsp
+
+import (
+	"time"
+)
+
+// Delay lengths (in frames at 44100 Hz) of the comb and allpass filters used by Freeverb.
+var (
+	freeverbCombs     = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	freeverbAllpasses = []int{556, 441, 341, 225}
+)
+
+// Algorithmic reverb (mono version of Jezar's Freeverb: 8 parallel damped combs followed by 4 allpasses).
+// The room size and damping go from 0 to 1,
+// mix goes from 0 (dry signal only) to 1 (reverberated signal only).
+func Reverb(in Signal, rate int, roomSize, damping, mix float64) Signal {
+	feedback := 0.7 + 0.28*roomSize
+	damp := 0.4 * damping
+	scale := func(n int) int { return n * rate / 44100 }
+
+	return Stateful(rate, func() func(x time.Duration) float64 {
+		combs := make([]*delayLine, len(freeverbCombs))
+		stores := make([]float64, len(freeverbCombs))
+		for i, n := range freeverbCombs {
+			combs[i] = newDelayLine(scale(n))
+		}
+		allpasses := make([]*delayLine, len(freeverbAllpasses))
+		for i, n := range freeverbAllpasses {
+			allpasses[i] = newDelayLine(scale(n))
+		}
+
+		return func(x time.Duration) (y float64) {
+			dry := in.At(x)
+			input := 0.015 * dry
+			var wet float64
+			for i, comb := range combs {
+				out := comb.read(float64(len(comb.buf)))
+				stores[i] = out*(1-damp) + stores[i]*damp
+				comb.write(input + stores[i]*feedback)
+				wet += out
+			}
+			for _, allpass := range allpasses {
+				out := allpass.read(float64(len(allpass.buf)))
+				allpass.write(wet + 0.5*out)
+				wet = out - wet
+			}
+			return (1-mix)*dry + mix*wet
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package dsp
+
+import (
+	"time"
+)
+
+// Plays a recorded sound at arbitrary pitches (by reading the buffer faster or slower).
+// The sound is played once (one-shot), unless loop points are set (LoopEnd > LoopStart),
+// in which case it loops between them once reaching LoopEnd (forever).
+//
+// Ex: piano, _ := dsp.LoadWAV("c4.wav"); dsp.Sampler{Buffer: piano, Root: music.C4.Hz()}.Play(music.E4.Hz())
+type Sampler struct {
+	Buffer    Buffer
+	Root      float64 // Frequency of the recorded sound (in Hertz), the buffer is played as is at this frequency
+	LoopStart time.Duration
+	LoopEnd   time.Duration
+}
+
+// Plays the sample at the given frequency (in Hertz), starting at x = 0.
+func (s Sampler) Play(freq float64) Signal {
+	speed := 1.0
+	if s.Root > 0 {
+		speed = freq / s.Root
+	}
+	loop := s.LoopEnd - s.LoopStart
+	return SignalFunc(func(x time.Duration) (y float64) {
+		pos := time.Duration(float64(x) * speed)
+		if loop > 0 && pos >= s.LoopEnd {
+			pos = s.LoopStart + (pos-s.LoopStart)%loop
+		}
+		return s.Buffer.At(pos)
+	})
+}
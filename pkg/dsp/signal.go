@@ -1,7 +1,6 @@
 package dsp
 
 import (
-	"encoding/binary"
 	"math"
 	"time"
 )
@@ -14,34 +13,20 @@ type SignalFunc func(x time.Duration) (y float64)
 
 func (f SignalFunc) At(x time.Duration) (y float64) { return f(x) }
 
-func Constant(v float64) Signal {
-	return SignalFunc(func(x time.Duration) float64 { return v })
-}
+func Constant(v float64) Signal { return constant(v) }
 
-func Sine(freq Signal) Signal {
-	return SignalFunc(func(x time.Duration) (y float64) {
-		return math.Sin(x.Seconds() * 2 * math.Pi * freq.At(x))
-	})
-}
+type constant float64
+
+func (c constant) At(x time.Duration) (y float64) { return float64(c) }
 
 func Sample(s Signal, rate int, from, to time.Duration) (frames []float64) {
-	step := float64(time.Second) / float64(rate)
-	for i := float64(from); i < float64(from+to); i += step {
-		val := s.At(time.Duration(i))
-		frames = append(frames, val)
+	frames = make([]float64, FrameCount(to, rate))
+	for i := range frames {
+		frames[i] = s.At(from + FrameTime(int64(i), rate))
 	}
 	return frames
 }
 
-func EncodePCM(frames []float64) (b []byte) {
-	var buf [8]byte
-	for _, pulse := range frames {
-		binary.BigEndian.PutUint64(buf[:], math.Float64bits(pulse))
-		b = append(b, buf[:]...)
-	}
-	return b
-}
-
 func Combine(signals ...Signal) Signal {
 	return SignalFunc(func(x time.Duration) (y float64) {
 		for _, s := range signals {
@@ -60,13 +45,18 @@ func F(d time.Duration, s Signal) FiniteSignal { return FiniteSignal{s, d} }
 
 func Blank(d time.Duration) FiniteSignal { return FiniteSignal{Constant(0), d} }
 
+// Plays the signals one after the other, looping forever (like LoopForever(SequenceOnce(signals...))).
 func Sequence(signals ...FiniteSignal) Signal {
+	return LoopForever(SequenceOnce(signals...))
+}
+
+// Plays the signals one after the other, only once (the signal is 0 before and after).
+func SequenceOnce(signals ...FiniteSignal) FiniteSignal {
 	totalDuration := time.Duration(0)
 	for _, s := range signals {
 		totalDuration += s.Duration
 	}
-	return SignalFunc(func(x time.Duration) (y float64) {
-		x = x % totalDuration
+	return F(totalDuration, SignalFunc(func(x time.Duration) (y float64) {
 		i := time.Duration(0)
 		for _, s := range signals {
 			if x >= i && x < i+s.Duration {
@@ -74,7 +64,74 @@ func Sequence(signals ...FiniteSignal) Signal {
 			}
 			i += s.Duration
 		}
-		panic("unreachable")
+		return 0
+	}))
+}
+
+// Finite signal starting at a given time on a timeline (see Overlay).
+type PlacedSignal struct {
+	At time.Duration
+	FiniteSignal
+}
+
+// Mixes the signals (summing them), each starting at its own time, ex: layering drums over chords over bass.
+// Each signal is 0 outside of its duration, and the overlay lasts until the end of the last one.
+//
+//	dsp.Overlay(
+//		dsp.PlacedSignal{At: 0, FiniteSignal: chords},
+//		dsp.PlacedSignal{At: bpm.T(4), FiniteSignal: drums},
+//		dsp.PlacedSignal{At: bpm.T(8), FiniteSignal: bass},
+//	)
+func Overlay(events ...PlacedSignal) FiniteSignal {
+	var total time.Duration
+	for _, e := range events {
+		total = max(total, e.At+e.Duration)
+	}
+	return F(total, SignalFunc(func(x time.Duration) (y float64) {
+		for _, e := range events {
+			if x >= e.At && x < e.At+e.Duration {
+				y += e.Signal.At(x - e.At)
+			}
+		}
+		return y
+	}))
+}
+
+// Plays the signal n times in a row (the signal is 0 before and after).
+func Loop(s FiniteSignal, n int) FiniteSignal {
+	total := time.Duration(max(n, 0)) * s.Duration
+	return F(total, SignalFunc(func(x time.Duration) (y float64) {
+		if x < 0 || x >= total {
+			return 0
+		}
+		return s.Signal.At(x % s.Duration)
+	}))
+}
+
+// Plays the signal over and over, in both directions of time (the signal is 0 if its duration is 0).
+func LoopForever(s FiniteSignal) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		if s.Duration <= 0 {
+			return 0
+		}
+		x %= s.Duration
+		if x < 0 {
+			x += s.Duration
+		}
+		return s.Signal.At(x)
+	})
+}
+
+// Moves the signal later in time (or earlier if by is negative), it is 0 before it starts.
+// Unlike Delay, the signal isn't mixed with its dry copy or repeated.
+//
+// Ex: starting the bass 4 beats after the drums, dsp.Combine(drums, dsp.Shift(bass, bpm.T(4)))
+func Shift(s Signal, by time.Duration) Signal {
+	return SignalFunc(func(x time.Duration) (y float64) {
+		if x < by {
+			return 0
+		}
+		return s.At(x - by)
 	})
 }
 
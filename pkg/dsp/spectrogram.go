@@ -0,0 +1,87 @@
+package dsp
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/cmplx"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp/fft"
+)
+
+type SpectrogramOptions struct {
+	Window  int     // Number of frames analyzed at once (rounded up to a power of 2), defaults to 2048
+	Overlap float64 // Proportion of each window shared with the next one (from 0 to 1), defaults to 0.75
+	Height  int     // Height of the image (in pixels), defaults to 512, the width is the number of windows
+	MinFreq float64 // Lowest frequency shown (in Hertz), defaults to 20
+	MaxFreq float64 // Highest frequency shown (in Hertz), defaults to half the sample rate
+	Range   float64 // Dynamic range shown (in dB below the loudest point), defaults to 90
+}
+
+// Writes a spectrogram of the frames as a PNG image: time goes from left to right,
+// frequency from bottom to top (on a logarithmic scale), and louder is brighter.
+// Each column is the magnitude spectrum of a (Hann-windowed) slice of the frames.
+//
+// Ex: dsp.SpectrogramPNG(f, dsp.Sample(s, 44100, 0, 10*time.Second), 44100, dsp.SpectrogramOptions{})
+func SpectrogramPNG(w io.Writer, frames []float64, rate int, opts SpectrogramOptions) error {
+	if opts.Window == 0 {
+		opts.Window = 2048
+	}
+	size := max(fft.Size(opts.Window), 2)
+	if opts.Overlap == 0 {
+		opts.Overlap = 0.75
+	}
+	hop := max(1, int(float64(size)*(1-opts.Overlap)))
+	if opts.Height == 0 {
+		opts.Height = 512
+	}
+	if opts.MinFreq == 0 {
+		opts.MinFreq = 20
+	}
+	if opts.MaxFreq == 0 {
+		opts.MaxFreq = float64(rate) / 2
+	}
+	if opts.Range == 0 {
+		opts.Range = 90
+	}
+
+	// Magnitudes (in dB) of each window, for each pixel row.
+	spectra := fft.STFT(frames, size, hop, fft.Hann)
+	columns := make([][]float64, len(spectra))
+	loudest := math.Inf(-1)
+	for x, spectrum := range spectra {
+		columns[x] = make([]float64, opts.Height)
+		for y := range columns[x] {
+			// Row 0 is at the top (highest frequency).
+			freq := opts.MinFreq * math.Pow(opts.MaxFreq/opts.MinFreq, 1-float64(y)/float64(max(opts.Height-1, 1)))
+			bin := freq * float64(size) / float64(rate)
+			k := min(int(bin), size/2-1)
+			t := bin - float64(k)
+			mag := (1-t)*cmplx.Abs(spectrum[k]) + t*cmplx.Abs(spectrum[k+1])
+			columns[x][y] = 20 * math.Log10(mag+1e-12)
+			loudest = math.Max(loudest, columns[x][y])
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(columns), opts.Height))
+	for x, column := range columns {
+		for y, db := range column {
+			img.Set(x, y, heat(1+(db-loudest)/opts.Range))
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// Returns the color of a value between 0 (black) and 1 (white), going through blue, red and yellow.
+func heat(v float64) color.RGBA {
+	v = math.Max(0, math.Min(1, v))
+	stops := []color.RGBA{{0, 0, 0, 255}, {40, 0, 120, 255}, {200, 0, 60, 255}, {255, 160, 0, 255}, {255, 255, 255, 255}}
+	pos := v * float64(len(stops)-1)
+	i := min(int(pos), len(stops)-2)
+	t := pos - float64(i)
+	lerp := func(a, b uint8) uint8 { return uint8(float64(a) + t*(float64(b)-float64(a))) }
+	a, b := stops[i], stops[i+1]
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 255}
+}
@@ -0,0 +1,35 @@
+package dsp
+
+import "time"
+
+// Returns a signal whose values depend on the previously computed ones (ex: filters, delays, etc.).
+//
+// Frames are computed one after the other at the given sample rate by the function returned by start,
+// which holds the state in its closure.
+// Stateful signals can still be accessed at any position x:
+// frames are computed up to x (starting from 0), and the last computed frame is returned.
+// Moving backwards calls start again to compute frames from the beginning with a fresh state.
+//
+// Stateful signals are meant to be sampled in order and are not safe for concurrent use.
+func Stateful(rate int, start func() (next func(x time.Duration) (y float64))) Signal {
+	return &stateful{rate: rate, start: start}
+}
+
+type stateful struct {
+	rate  int
+	start func() func(x time.Duration) float64
+	next  func(x time.Duration) float64
+	frame int64 // Index of the next frame to compute
+	y     float64
+}
+
+func (s *stateful) At(x time.Duration) (y float64) {
+	n := FrameAt(x, s.rate)
+	if s.next == nil || n < s.frame-1 {
+		s.next, s.frame, s.y = s.start(), 0, 0
+	}
+	for ; s.frame <= n; s.frame++ {
+		s.y = s.next(FrameTime(s.frame, s.rate))
+	}
+	return s.y
+}
@@ -0,0 +1,60 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Stereo signal, made of a left and a right channel.
+type Stereo struct {
+	Left, Right Signal
+}
+
+// Plays the same signal on both channels.
+func Mono(s Signal) Stereo { return Stereo{s, s} }
+
+// Places a mono signal in the stereo field, pos goes from -1 (left) to 1 (right).
+// Uses an equal-power pan law so the perceived loudness stays the same across positions.
+func Pan(in Signal, pos Signal) Stereo {
+	angle := func(x time.Duration) float64 { return (math.Max(-1, math.Min(1, pos.At(x))) + 1) * math.Pi / 4 }
+	return Stereo{
+		Left:  SignalFunc(func(x time.Duration) (y float64) { return in.At(x) * math.Cos(angle(x)) }),
+		Right: SignalFunc(func(x time.Duration) (y float64) { return in.At(x) * math.Sin(angle(x)) }),
+	}
+}
+
+func CombineStereo(signals ...Stereo) Stereo {
+	left, right := make([]Signal, len(signals)), make([]Signal, len(signals))
+	for i, s := range signals {
+		left[i], right[i] = s.Left, s.Right
+	}
+	return Stereo{Combine(left...), Combine(right...)}
+}
+
+func AmplifyStereo(v Stereo, by Signal) Stereo {
+	return Stereo{Amplify(v.Left, by), Amplify(v.Right, by)}
+}
+
+// Like Sample, but returns interleaved frames (L, R, L, R, ...), ready to be encoded with 2 channels.
+func SampleStereo(s Stereo, rate int, from, to time.Duration) (frames []float64) {
+	frames = make([]float64, 0, 2*FrameCount(to, rate))
+	for i := range FrameCount(to, rate) {
+		x := from + FrameTime(i, rate)
+		frames = append(frames, s.Left.At(x), s.Right.At(x))
+	}
+	return frames
+}
+
+// Interleaves the frames of several channels (of the same length).
+func Interleave(channels ...[]float64) (frames []float64) {
+	if len(channels) == 0 {
+		return nil
+	}
+	frames = make([]float64, 0, len(channels)*len(channels[0]))
+	for i := range channels[0] {
+		for _, c := range channels {
+			frames = append(frames, c[i])
+		}
+	}
+	return frames
+}
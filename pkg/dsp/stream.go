@@ -0,0 +1,39 @@
+package dsp
+
+import (
+	"io"
+	"time"
+)
+
+// Number of frames rendered and encoded at once by Stream.
+const StreamChunkSize = 4096
+
+// Samples s (like Sample) and writes the encoded PCM frames to w,
+// one chunk at a time, so that memory usage does not depend on the length of the render.
+func Stream(w io.Writer, s Signal, rate int, from, to time.Duration, opts EncodeOptions) error {
+	frames := make([]float64, 0, StreamChunkSize)
+	b := make([]byte, 0, StreamChunkSize*opts.Format.Size())
+	e := newEncoder(opts, opts.byteOrder(), rate)
+	flush := func() error {
+		b = b[:0]
+		for _, pulse := range frames {
+			b = e.append(b, pulse)
+		}
+		frames = frames[:0]
+		_, err := w.Write(b)
+		return err
+	}
+
+	for i := range FrameCount(to, rate) {
+		frames = append(frames, s.At(from+FrameTime(i, rate)))
+		if len(frames) == cap(frames) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(frames) > 0 {
+		return flush()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Length of the windows (grains) used by TimeStretch.
+// Longer windows suit sustained sounds, shorter ones keep transients sharper.
+const StretchWindow = 40 * time.Millisecond
+
+// Changes the duration of the buffer by the given factor (2 is twice as long) without changing its pitch.
+// This uses WSOLA: overlapping windows of the input are copied to the output at a different pace,
+// each window being picked (near its nominal position) so that it lines up with the previous one
+// and doesn't create phase cancellations.
+//
+// Ex: conforming a 120 BPM loop to 127 BPM: dsp.TimeStretch(loop, 120.0/127)
+func TimeStretch(b Buffer, factor float64) Buffer {
+	size := max(int(StretchWindow.Seconds()*float64(b.Rate)), 4)
+	synthesisHop := size / 2
+	analysisHop := float64(synthesisHop) / factor
+	tolerance := size / 4
+
+	window := make([]float64, size) // Hann windows overlapping by half sum to 1
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size))
+	}
+	in := func(i int) float64 {
+		if i < 0 || i >= len(b.Frames) {
+			return 0
+		}
+		return b.Frames[i]
+	}
+
+	out := make([]float64, int(math.Round(float64(len(b.Frames))*factor)))
+	weights := make([]float64, len(out))
+	prev := 0 // Input position of the previous window
+	for k := 0; k*synthesisHop < len(out); k++ {
+		pos := int(math.Round(float64(k) * analysisHop))
+		if k > 0 {
+			// Find the window most similar to what would naturally follow the previous one.
+			next, best := prev+synthesisHop, math.Inf(-1)
+			from := pos
+			for offset := -tolerance; offset <= tolerance; offset++ {
+				corr := 0.0
+				for i := 0; i < size; i += 2 {
+					corr += in(next+i) * in(from+offset+i)
+				}
+				if corr > best {
+					best, pos = corr, from+offset
+				}
+			}
+		}
+		for i, w := range window {
+			if j := k*synthesisHop + i; j < len(out) {
+				out[j] += w * in(pos+i)
+				weights[j] += w
+			}
+		}
+		prev = pos
+	}
+	for i, w := range weights {
+		if w > 1e-3 {
+			out[i] /= w
+		}
+	}
+	return Buffer{Frames: out, Rate: b.Rate}
+}
+
+// Changes the pitch of the buffer (by the given number of semitones) without changing its duration.
+// The buffer is time-stretched and then resampled back to its original duration.
+func PitchShift(b Buffer, semitones float64) Buffer {
+	ratio := math.Pow(2, semitones/12)
+	stretched := TimeStretch(b, ratio)
+	return Buffer{Frames: Resample(stretched.Frames, int(math.Round(float64(b.Rate)*ratio)), b.Rate), Rate: b.Rate}
+}
@@ -0,0 +1,28 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// Stacks several copies of an oscillator, detuned (up to ± detuneCents) and spread across the stereo field
+// (spread goes from 0, all voices in the center, to 1, outermost voices hard left and right).
+// Each voice starts at a different (but deterministic) phase, so renders are reproducible.
+//
+// Ex: supersaw: dsp.Unison(dsp.Saw, 7, 25, 0.8)(music.A4)
+func Unison(osc Oscillator, voices int, detuneCents, spread float64) func(freq Signal) Stereo {
+	return func(freq Signal) Stereo {
+		if voices <= 1 {
+			return Mono(osc(freq))
+		}
+		stereo := make([]Stereo, voices)
+		for i := range stereo {
+			pos := 2*float64(i)/float64(voices-1) - 1 // From -1 to 1
+			ratio := math.Pow(2, pos*detuneCents/1200)
+			detuned := SignalFunc(func(x time.Duration) (y float64) { return ratio * freq.At(x) })
+			phase := frac(float64(i) * math.Phi) // Golden ratio: phases are spread as evenly as possible
+			stereo[i] = Pan(PhaseShift(osc, phase)(detuned), Constant(pos*spread))
+		}
+		return CombineStereo(stereo...)
+	}
+}
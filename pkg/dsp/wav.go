@@ -0,0 +1,165 @@
+package dsp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Encodes frames as a WAV file (RIFF header followed by little-endian PCM data).
+// When channels > 1, frames must be interleaved (L, R, L, R, ...).
+func EncodeWAV(frames []float64, rate, channels int, opts EncodeOptions) (b []byte) {
+	b = appendWAVHeader(make([]byte, 0, 64+len(frames)*opts.Format.Size()), len(frames), rate, channels, opts)
+	e := newEncoder(opts, binary.LittleEndian, rate)
+	for _, pulse := range frames {
+		b = e.append(b, pulse)
+	}
+	return b
+}
+
+// Samples s (like Sample) and writes it to w as a mono WAV file, one chunk at a time (see Stream),
+// so that memory usage does not depend on the length of the render.
+func StreamWAV(w io.Writer, s Signal, rate int, from, to time.Duration, opts EncodeOptions) error {
+	if _, err := w.Write(appendWAVHeader(nil, int(FrameCount(to, rate)), rate, 1, opts)); err != nil {
+		return err
+	}
+	opts.LittleEndian = true
+	return Stream(w, s, rate, from, to, opts)
+}
+
+// Appends the RIFF header and the chunks preceding the frames, for the given number of frames (including all channels).
+func appendWAVHeader(b []byte, frames, rate, channels int, opts EncodeOptions) []byte {
+	size := opts.Format.Size()
+	blockAlign := channels * size
+	dataSize := frames * size
+
+	// Non-integer formats require an extended "fmt " chunk and a "fact" chunk.
+	formatTag, fmtSize, factSize := uint16(1), 16, 0
+	if opts.Format.IsFloat() {
+		formatTag, fmtSize, factSize = 3, 18, 12
+	}
+
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+8+fmtSize+factSize+8+dataSize))
+	b = append(b, "WAVE"...)
+
+	b = append(b, "fmt "...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(fmtSize))
+	b = binary.LittleEndian.AppendUint16(b, formatTag)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate*blockAlign))
+	b = binary.LittleEndian.AppendUint16(b, uint16(blockAlign))
+	b = binary.LittleEndian.AppendUint16(b, uint16(size*8))
+	if fmtSize == 18 {
+		b = binary.LittleEndian.AppendUint16(b, 0)
+	}
+
+	if factSize > 0 {
+		b = append(b, "fact"...)
+		b = binary.LittleEndian.AppendUint32(b, 4)
+		b = binary.LittleEndian.AppendUint32(b, uint32(frames/channels))
+	}
+
+	b = append(b, "data"...)
+	return binary.LittleEndian.AppendUint32(b, uint32(dataSize))
+}
+
+// Reads a WAV file into a buffer (channels are mixed down to mono).
+func LoadWAV(path string) (b Buffer, err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	frames, rate, channels, err := DecodeWAV(raw)
+	if err != nil {
+		return b, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return mixDown(frames, rate, channels), nil
+}
+
+// Returns the average of the channels of interleaved frames.
+func mixDown(frames []float64, rate, channels int) (b Buffer) {
+	b = Buffer{Frames: make([]float64, len(frames)/channels), Rate: rate}
+	for i := range b.Frames {
+		for c := range channels {
+			b.Frames[i] += frames[i*channels+c]
+		}
+		b.Frames[i] /= float64(channels)
+	}
+	return b
+}
+
+// Decodes a WAV file into frames (interleaved when channels > 1), along with its sample rate.
+// Integer frames are scaled to [-1, 1] (the inverse of EncodeWAV).
+func DecodeWAV(b []byte) (frames []float64, rate, channels int, err error) {
+	if len(b) < 12 || string(b[:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, 0, 0, errors.New("not a WAV file")
+	}
+	var format SampleFormat
+	hasFormat := false
+	for b = b[12:]; len(b) >= 8; {
+		id, size := string(b[:4]), int(binary.LittleEndian.Uint32(b[4:8]))
+		b = b[8:]
+		if size > len(b) {
+			size = len(b) // Some encoders write an invalid size for the last chunk when streaming.
+		}
+		chunk := b[:size]
+		b = b[min(size+size%2, len(b)):] // Chunks are padded to an even size.
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, 0, errors.New("invalid fmt chunk")
+			}
+			tag := binary.LittleEndian.Uint16(chunk[0:2])
+			channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			rate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			bits := int(binary.LittleEndian.Uint16(chunk[14:16]))
+			if tag == 0xFFFE && size >= 26 { // WAVE_FORMAT_EXTENSIBLE: the actual tag starts the sub-format GUID.
+				tag = binary.LittleEndian.Uint16(chunk[24:26])
+			}
+			format, err = wavFormat(tag, bits)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if channels < 1 {
+				return nil, 0, 0, errors.New("invalid number of channels")
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, 0, 0, errors.New("missing fmt chunk before data chunk")
+			}
+			size := format.Size()
+			n := len(chunk) / size / channels * channels
+			frames = make([]float64, n)
+			for i := range frames {
+				frames[i] = decodeFrame(chunk[i*size:], binary.LittleEndian, format)
+			}
+			return frames, rate, channels, nil
+		}
+	}
+	return nil, 0, 0, errors.New("missing data chunk")
+}
+
+func wavFormat(tag uint16, bits int) (SampleFormat, error) {
+	switch {
+	case tag == 1 && bits == 8:
+		return Uint8, nil
+	case tag == 1 && bits == 16:
+		return Int16, nil
+	case tag == 1 && bits == 24:
+		return Int24, nil
+	case tag == 1 && bits == 32:
+		return Int32, nil
+	case tag == 3 && bits == 32:
+		return Float32, nil
+	case tag == 3 && bits == 64:
+		return Float64, nil
+	}
+	return 0, fmt.Errorf("unsupported WAV format (tag %d, %d bits)", tag, bits)
+}
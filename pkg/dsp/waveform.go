@@ -0,0 +1,65 @@
+package dsp
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+type WaveformOptions struct {
+	Background color.Color // Defaults to white
+	Color      color.Color // Defaults to dark blue
+	Clip       color.Color // Color of columns where frames go beyond [-1, 1], defaults to red
+	Scale      float64     // Value shown at the top of the image, defaults to 1 (values beyond are cut off)
+}
+
+// Writes the waveform of the frames as a PNG image:
+// each column is a vertical line from the lowest to the highest frame it covers.
+//
+// Ex: dsp.WaveformPNG(f, dsp.Sample(s, 44100, 0, 10*time.Second), 1200, 300, dsp.WaveformOptions{})
+func WaveformPNG(w io.Writer, frames []float64, width, height int, opts WaveformOptions) error {
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+	if opts.Color == nil {
+		opts.Color = color.RGBA{20, 40, 120, 255}
+	}
+	if opts.Clip == nil {
+		opts.Clip = color.RGBA{220, 20, 20, 255}
+	}
+	if opts.Scale == 0 {
+		opts.Scale = 1
+	}
+	width, height = max(width, 1), max(height, 1)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, opts.Background)
+		}
+	}
+	row := func(v float64) int {
+		y := int(math.Round((1 - v/opts.Scale) / 2 * float64(height-1)))
+		return max(0, min(height-1, y))
+	}
+	for x := range width {
+		from, to := x*len(frames)/width, (x+1)*len(frames)/width
+		if from >= len(frames) {
+			break
+		}
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, v := range frames[from:max(to, from+1)] {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		c := opts.Color
+		if lo < -1 || hi > 1 {
+			c = opts.Clip
+		}
+		for y := row(hi); y <= row(lo); y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return png.Encode(w, img)
+}
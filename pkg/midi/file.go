@@ -0,0 +1,81 @@
+package midi
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+// Resolution of the MIDI files written by WriteFile (in ticks per quarter note).
+const TicksPerQuarter = 480
+
+// Writes the notes as a Standard MIDI File (format 0, on channel 0), at the given tempo.
+// Notes outside of the MIDI range (0 to 127) are an error.
+func WriteFile(w io.Writer, notes music.PianoRoll, bpm music.BPM) error {
+	type event struct {
+		tick int64
+		msg  Message
+	}
+	ticks := func(beats float64) int64 { return int64(math.Round(beats * TicksPerQuarter)) }
+	events := make([]event, 0, 2*len(notes))
+	for _, n := range notes {
+		key := n.Note.MIDI()
+		if key < 0 || key > 127 {
+			return fmt.Errorf("note %s at %s: out of the MIDI range", n.Note, n.Start)
+		}
+		velocity := byte(max(1, min(127, math.Round(n.Velocity*127))))
+		start := ticks(n.Start.Minutes() * float64(bpm))
+		end := max(ticks((n.Start+n.Duration).Minutes()*float64(bpm)), start+1) // The note off must come after the note on
+		events = append(events,
+			event{start, Message{Kind: NoteOn, Data: [2]byte{byte(key), velocity}}},
+			event{end, Message{Kind: NoteOff, Data: [2]byte{byte(key), 0}}},
+		)
+	}
+	// Note offs go first so that repeated notes are not cut.
+	slices.SortStableFunc(events, func(a, b event) int {
+		if a.tick != b.tick {
+			return cmp.Compare(a.tick, b.tick)
+		}
+		return cmp.Compare(a.msg.Kind, b.msg.Kind)
+	})
+
+	var track bytes.Buffer
+	tempo := uint32(math.Round(6e7 / float64(bpm))) // Microseconds per quarter note
+	track.Write([]byte{0x00, 0xFF, 0x51, 0x03, byte(tempo >> 16), byte(tempo >> 8), byte(tempo)})
+	var last int64
+	for _, e := range events {
+		track.Write(appendVarLen(nil, uint32(e.tick-last)))
+		track.Write(e.msg.Bytes())
+		last = e.tick
+	}
+	track.Write([]byte{0x00, 0xFF, 0x2F, 0x00}) // End of track
+
+	b := []byte("MThd")
+	b = binary.BigEndian.AppendUint32(b, 6)
+	b = binary.BigEndian.AppendUint16(b, 0) // Format 0
+	b = binary.BigEndian.AppendUint16(b, 1) // Number of tracks
+	b = binary.BigEndian.AppendUint16(b, TicksPerQuarter)
+	b = append(b, "MTrk"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(track.Len()))
+	b = append(b, track.Bytes()...)
+	_, err := w.Write(b)
+	return err
+}
+
+// Appends a variable-length quantity (7 bits per byte, most significant first).
+func appendVarLen(b []byte, v uint32) []byte {
+	var buf [5]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7F)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7F) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
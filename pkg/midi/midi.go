@@ -0,0 +1,125 @@
+// Package midi reads and writes MIDI messages, and plays them live on a polyphonic synth.
+//
+// Live input works with any raw MIDI byte stream,
+// such as ALSA raw MIDI devices on Linux (ex: os.Open("/dev/snd/midiC1D0")).
+package midi
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+type Kind int
+
+const (
+	NoteOff Kind = iota + 1
+	NoteOn
+	PolyAftertouch
+	ControlChange
+	ProgramChange
+	ChannelAftertouch
+	PitchBend
+)
+
+// Channel voice message.
+type Message struct {
+	Kind    Kind
+	Channel int // From 0 to 15
+	Data    [2]byte
+}
+
+func (m Message) Note() music.Note { return music.FromMIDI(int(m.Data[0])) }
+func (m Message) Velocity() int    { return int(m.Data[1]) }
+
+// Returns the pitch bend amount, from -1 to 1 (for PitchBend messages).
+func (m Message) Bend() float64 { return float64((int(m.Data[1])<<7|int(m.Data[0]))-8192) / 8192 }
+
+// Reports whether the message starts a note (note on messages with a velocity of 0 are note offs).
+func (m Message) IsNoteOn() bool { return m.Kind == NoteOn && m.Velocity() > 0 }
+func (m Message) IsNoteOff() bool {
+	return m.Kind == NoteOff || (m.Kind == NoteOn && m.Velocity() == 0)
+}
+
+// Returns the encoded message (with its status byte).
+func (m Message) Bytes() []byte {
+	b := []byte{byte(0x80+(int(m.Kind)-1)<<4) | byte(m.Channel&0x0F), m.Data[0] & 0x7F}
+	if dataSize(b[0]) == 2 {
+		b = append(b, m.Data[1]&0x7F)
+	}
+	return b
+}
+
+// Reads channel voice messages from a raw MIDI byte stream,
+// system messages (SysEx, clock, etc.) are skipped.
+type Reader struct {
+	r      *bufio.Reader
+	status byte // Running status
+}
+
+func NewReader(r io.Reader) *Reader { return &Reader{r: bufio.NewReader(r)} }
+
+func (r *Reader) Read() (Message, error) {
+	var data [2]byte
+	n := 0
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return Message{}, err
+		}
+		switch {
+		case b >= 0xF8: // Real-time messages can appear anywhere
+			continue
+		case b == 0xF0: // SysEx, skip until the end
+			if _, err := r.r.ReadBytes(0xF7); err != nil {
+				return Message{}, err
+			}
+			r.status = 0
+			continue
+		case b >= 0xF0: // System common messages
+			r.status = 0
+			if _, err := r.r.Discard(systemDataSize[b]); err != nil {
+				return Message{}, err
+			}
+			continue
+		case b >= 0x80:
+			r.status, n = b, 0
+			continue
+		case r.status == 0: // Data byte without status
+			continue
+		}
+
+		data[n] = b
+		n++
+		if n == dataSize(r.status) {
+			return Message{Kind: Kind(r.status>>4 - 7), Channel: int(r.status & 0x0F), Data: data}, nil
+		}
+	}
+}
+
+// Reads messages from r and passes them to handle, until r is exhausted (or fails).
+func Listen(r io.Reader, handle func(Message)) error {
+	mr := NewReader(r)
+	for {
+		m, err := mr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		handle(m)
+	}
+}
+
+// Number of data bytes following system common status bytes.
+var systemDataSize = map[byte]int{0xF1: 1, 0xF2: 2, 0xF3: 1}
+
+// Number of data bytes following a channel status byte.
+func dataSize(status byte) int {
+	if status>>4 == 0xC || status>>4 == 0xD {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,93 @@
+package midi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+// Polyphonic signal played live (ex: with a MIDI keyboard, through Listen and Handle).
+// Pitch bend messages bend all the notes, including the ones already playing.
+//
+// Notes start (and stop) at the last position the synth was sampled at,
+// so the latency is the amount of audio rendered ahead of playback:
+//
+//	synth := &midi.Synth{Instrument: music.Oscillator(dsp.Saw)}
+//	go midi.Listen(device, synth.Handle)
+//	playback.Play(ctx, synth, time.Hour, playback.Options{Buffer: 20 * time.Millisecond})
+type Synth struct {
+	Instrument music.Instrument
+	Attack     time.Duration // Fade in when a note starts, defaults to 5ms
+	Release    time.Duration // Fade out when a note stops, defaults to 50ms
+	BendRange  float64       // Pitch bend at the ends of the pitch wheel (in semitones), defaults to 2
+
+	mu    sync.Mutex
+	now   time.Duration
+	bend  float64 // In semitones
+	synth *music.Synth
+}
+
+// Starts or stops voices according to the message.
+func (s *Synth) Handle(m Message) {
+	switch {
+	case m.IsNoteOn():
+		s.NoteOn(m.Note(), float64(m.Velocity())/127)
+	case m.IsNoteOff():
+		s.NoteOff(m.Note())
+	case m.Kind == PitchBend:
+		s.Bend(m.Bend())
+	}
+}
+
+func (s *Synth) NoteOn(n music.Note, velocity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voices().NoteOn(s.now, n, velocity)
+}
+
+func (s *Synth) NoteOff(n music.Note) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voices().NoteOff(s.now, n)
+}
+
+// Sets the pitch bend, from -1 to 1 (see BendRange).
+func (s *Synth) Bend(amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bendRange := s.BendRange
+	if bendRange == 0 {
+		bendRange = 2
+	}
+	s.bend = amount * bendRange
+}
+
+func (s *Synth) At(x time.Duration) (y float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = max(s.now, x)
+	voices := s.voices()
+	voices.Prune(s.now)
+	return voices.At(x)
+}
+
+// Returns the synth playing the voices (created on first use).
+func (s *Synth) voices() *music.Synth {
+	if s.synth == nil {
+		env := music.DefaultEnvelope
+		if s.Attack != 0 {
+			env.Attack = s.Attack
+		}
+		if s.Release != 0 {
+			env.Release = s.Release
+		}
+		bend := dsp.SignalFunc(func(x time.Duration) (y float64) { return s.bend }) // Read while sampling, with the lock held
+		instrument := func(n music.Note, velocity float64) dsp.Signal {
+			return dsp.Bend(s.Instrument(n, velocity), bend)
+		}
+		s.synth = &music.Synth{Instrument: instrument, Envelope: env}
+	}
+	return s.synth
+}
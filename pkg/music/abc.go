@@ -0,0 +1,649 @@
+package music
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Tune loaded from ABC notation (see ParseABC).
+type Tune struct {
+	Title  string
+	Meter  Meter // Zero for free meter
+	Key    Key
+	BPM    BPM // Defaults to 120
+	Notes  PianoRoll
+	Length time.Duration
+}
+
+// Returns the signal of the tune played by a synth using the given instrument.
+func (t Tune) Signal(instrument Instrument) dsp.FiniteSignal {
+	return playNotes(t.Notes, t.Length, instrument)
+}
+
+// Parses the first tune of an ABC file (see https://abcnotation.com/wiki/abc:standard:v2.1).
+//
+// It supports the title (T:), meter (M:), unit note length (L:), tempo (Q:) and key (K:) fields,
+// notes with accidentals, octave marks and lengths, rests, chords, ties, broken rhythms, tuplets,
+// repeats and first and second endings (repeats are played out in Notes).
+// Decorations, annotations, chord symbols, grace notes, slurs and lyrics are ignored,
+// tunes with multiple voices are not supported.
+//
+// Ex:
+//
+//	X:1
+//	T:Example
+//	M:6/8
+//	L:1/8
+//	Q:3/8=100
+//	K:G
+//	|:GAB c2d|e2f g3:|
+func ParseABC(r io.Reader) (Tune, error) {
+	p := &abcParser{tune: Tune{BPM: 120}}
+	sc := bufio.NewScanner(r)
+	inBody := false
+	for num := 1; sc.Scan(); num++ {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "%") {
+			continue
+		}
+		if inBody && (line == "" || strings.HasPrefix(line, "X:")) {
+			break // End of the first tune
+		}
+		var err error
+		if isABCField(line) {
+			err = p.field(line[0], strings.TrimSpace(stripABCComment(line[2:])), inBody)
+			inBody = inBody || line[0] == 'K'
+		} else if inBody {
+			err = p.body(line)
+		}
+		if err != nil {
+			return Tune{}, fmt.Errorf("abc: line %d: %w", num, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Tune{}, fmt.Errorf("abc: %w", err)
+	}
+	if !inBody {
+		return Tune{}, fmt.Errorf("abc: missing key field (K:)")
+	}
+	p.schedule()
+	return p.tune, nil
+}
+
+type abcParser struct {
+	tune      Tune
+	unit      float64 // Unit note length (in quarter notes), 0 until set by the header
+	tempo     string  // Value of the tempo field (which depends on the unit note length)
+	signature [7]int  // Accidental (in semitones) of each letter (from C) in the key signature
+	bar       map[[2]int]int
+	voice     string
+	items     []abcItem
+
+	lastNote       int     // Index of the last note in items (-1 if none)
+	nextMultiplier float64 // Length multiplier of the next note (for broken rhythms)
+	tupletLeft     int     // Number of notes left in the current tuplet
+	tupletRatio    float64
+}
+
+type abcItemKind int
+
+const (
+	abcNotes abcItemKind = iota
+	abcBar
+	abcRepeatStart
+	abcRepeatEnd
+	abcEnding
+)
+
+type abcItem struct {
+	kind   abcItemKind
+	notes  []Note  // Notes played at once (none for rests)
+	beats  float64 // Length (in quarter notes)
+	tie    bool    // Whether the notes are tied to the same notes in the next item
+	ending int     // Number of the ending (for abcEnding)
+}
+
+var abcLetters = "CDEFGAB"
+
+func isABCField(line string) bool {
+	return len(line) >= 2 && line[1] == ':' && ('A' <= line[0] && line[0] <= 'Z' || 'a' <= line[0] && line[0] <= 'z')
+}
+
+func stripABCComment(s string) string {
+	s, _, _ = strings.Cut(s, "%")
+	return s
+}
+
+func (p *abcParser) field(name byte, value string, inBody bool) error {
+	switch name {
+	case 'T':
+		if p.tune.Title == "" {
+			p.tune.Title = value
+		}
+	case 'M':
+		m, err := parseABCMeter(value)
+		if err != nil {
+			return err
+		}
+		p.tune.Meter = m
+	case 'L':
+		v, err := parseABCFraction(value)
+		if err != nil {
+			return fmt.Errorf("invalid unit note length %q", value)
+		}
+		p.unit = 4 * v
+	case 'Q':
+		if !inBody {
+			p.tempo = value
+		}
+	case 'K':
+		key, signature, err := parseABCKey(value)
+		if err != nil {
+			return err
+		}
+		p.tune.Key, p.signature = key, signature
+		if !inBody {
+			return p.startBody()
+		}
+	case 'V':
+		id := firstField(value)
+		if p.voice != "" && id != p.voice {
+			return fmt.Errorf("multiple voices are not supported")
+		}
+		p.voice = id
+	}
+	return nil
+}
+
+// Applies the defaults that depend on the header.
+func (p *abcParser) startBody() error {
+	p.lastNote = -1
+	if p.unit == 0 {
+		p.unit = 0.5
+		if m := p.tune.Meter; m.Unit != 0 && float64(m.Beats)/float64(m.Unit) < 0.75 {
+			p.unit = 0.25
+		}
+	}
+	if p.tempo != "" {
+		bpm, err := parseABCTempo(p.tempo, p.unit)
+		if err != nil {
+			return err
+		}
+		if bpm > 0 {
+			p.tune.BPM = bpm
+		}
+	}
+	return nil
+}
+
+// Parses a line of music.
+func (p *abcParser) body(line string) error {
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '%':
+			return nil
+		case c == '"' || c == '!' || c == '+': // Annotation, chord symbol or decoration
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("unterminated %q", c)
+			}
+			i += end + 2
+		case c == '{': // Grace notes
+			end := strings.IndexByte(line[i:], '}')
+			if end < 0 {
+				return fmt.Errorf("unterminated grace notes")
+			}
+			i += end + 1
+		case c == '(' && i+1 < len(line) && isDigit(line[i+1]):
+			i = p.tuplet(line, i+1)
+		case c == '[' && i+1 < len(line) && isDigit(line[i+1]):
+			n, j := readInt(line, i+1)
+			p.items = append(p.items, abcItem{kind: abcEnding, ending: n})
+			i = j
+		case c == '[' && i+2 < len(line) && line[i+2] == ':': // Inline field
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				return fmt.Errorf("unterminated inline field")
+			}
+			if err := p.field(line[i+1], strings.TrimSpace(line[i+3:i+end]), true); err != nil {
+				return err
+			}
+			i += end + 1
+		case c == '[' && i+1 < len(line) && line[i+1] != '|':
+			j, err := p.chord(line, i+1)
+			if err != nil {
+				return err
+			}
+			i = j
+		case c == '|' || c == ':' || c == '[' || c == ']':
+			i = p.barLine(line, i)
+		case strings.IndexByte("^_=", c) >= 0 || strings.IndexByte(abcLetters, upper(c)) >= 0:
+			n, mult, j, err := p.note(line, i)
+			if err != nil {
+				return err
+			}
+			p.addNotes([]Note{n}, mult)
+			i = j
+		case c == 'z' || c == 'x': // Rest (x is an invisible rest)
+			mult, j := readLength(line, i+1)
+			p.addNotes(nil, mult)
+			i = j
+		case c == 'Z' || c == 'X': // Rest lasting whole bars
+			n, j := readInt(line, i+1)
+			quarters := p.tune.Meter.Quarters()
+			if p.tune.Meter.Unit == 0 {
+				quarters = 4
+			}
+			p.items = append(p.items, abcItem{kind: abcNotes, beats: float64(max(n, 1)) * quarters})
+			i = j
+		case c == '>' || c == '<':
+			i = p.brokenRhythm(line, i)
+		case c == '-':
+			if p.lastNote >= 0 {
+				p.items[p.lastNote].tie = true
+			}
+			i++
+		default: // Spaces, slurs, decoration shortcuts, etc.
+			i++
+		}
+	}
+	return nil
+}
+
+// Parses a note (accidentals, letter, octave marks and length),
+// the length is returned as a multiple of the unit note length.
+func (p *abcParser) note(line string, i int) (n Note, mult float64, next int, err error) {
+	accidental, explicit := 0, false
+	for ; i < len(line) && strings.IndexByte("^_=", line[i]) >= 0; i++ {
+		explicit = true
+		switch line[i] {
+		case '^':
+			accidental++
+		case '_':
+			accidental--
+		}
+	}
+	if i >= len(line) || strings.IndexByte(abcLetters, upper(line[i])) < 0 {
+		return 0, 0, i, fmt.Errorf("expected a note after accidental")
+	}
+	letter := strings.IndexByte(abcLetters, upper(line[i]))
+	octave := 4
+	if line[i] >= 'a' {
+		octave = 5
+	}
+	for i++; i < len(line) && (line[i] == '\'' || line[i] == ','); i++ {
+		if line[i] == '\'' {
+			octave++
+		} else {
+			octave--
+		}
+	}
+
+	// Accidentals last until the end of the bar, for the same note in the same octave.
+	if p.bar == nil {
+		p.bar = map[[2]int]int{}
+	}
+	pos := [2]int{letter, octave}
+	if explicit {
+		p.bar[pos] = accidental
+	} else if v, ok := p.bar[pos]; ok {
+		accidental = v
+	} else {
+		accidental = p.signature[letter]
+	}
+	n = Note((octave+1)*12 + letterSemitones[rune(abcLetters[letter])] + accidental)
+	mult, i = readLength(line, i)
+	return n, mult, i, nil
+}
+
+// Parses a chord (after the opening bracket).
+func (p *abcParser) chord(line string, i int) (next int, err error) {
+	var notes []Note
+	mult := 0.0
+	for i < len(line) && line[i] != ']' {
+		if strings.IndexByte("^_=", line[i]) < 0 && strings.IndexByte(abcLetters, upper(line[i])) < 0 {
+			i++ // Decorations, ties inside the chord, etc.
+			continue
+		}
+		n, m, j, err := p.note(line, i)
+		if err != nil {
+			return j, err
+		}
+		if len(notes) == 0 {
+			mult = m // The chord is as long as its first note
+		}
+		notes = append(notes, n)
+		i = j
+	}
+	if i >= len(line) {
+		return i, fmt.Errorf("unterminated chord")
+	}
+	m, i := readLength(line, i+1)
+	if len(notes) > 0 {
+		p.addNotes(notes, mult*m)
+	}
+	return i, nil
+}
+
+func (p *abcParser) addNotes(notes []Note, mult float64) {
+	beats := p.unit * mult
+	if p.nextMultiplier != 0 {
+		beats, p.nextMultiplier = beats*p.nextMultiplier, 0
+	}
+	if p.tupletLeft > 0 {
+		beats *= p.tupletRatio
+		p.tupletLeft--
+	}
+	p.items = append(p.items, abcItem{kind: abcNotes, notes: notes, beats: beats})
+	p.lastNote = len(p.items) - 1
+}
+
+// Parses a bar line, possibly with repeats and an ending (ex: "|", "||", "|]", "|:", ":|", "::", ":|2").
+func (p *abcParser) barLine(line string, i int) (next int) {
+	start := i
+	for i < len(line) && strings.IndexByte("|:[]", line[i]) >= 0 {
+		if line[i] == '[' && (i+1 >= len(line) || line[i+1] != '|') {
+			break // Start of an ending, chord or inline field
+		}
+		i++
+	}
+	if i == start {
+		return i + 1 // Lone bracket
+	}
+	token := line[start:i]
+	clear(p.bar)
+	switch {
+	case strings.Trim(token, ":") == "": // "::"
+		p.items = append(p.items, abcItem{kind: abcRepeatEnd}, abcItem{kind: abcRepeatStart})
+	default:
+		if strings.HasPrefix(token, ":") {
+			p.items = append(p.items, abcItem{kind: abcRepeatEnd})
+		}
+		if strings.HasSuffix(token, ":") {
+			p.items = append(p.items, abcItem{kind: abcRepeatStart})
+		}
+		p.items = append(p.items, abcItem{kind: abcBar})
+	}
+	if i < len(line) && isDigit(line[i]) {
+		n, j := readInt(line, i)
+		p.items = append(p.items, abcItem{kind: abcEnding, ending: n})
+		i = j
+	}
+	return i
+}
+
+// Parses a tuplet ("(p", "(p:q" or "(p:q:r", after the opening parenthesis).
+func (p *abcParser) tuplet(line string, i int) (next int) {
+	n, i := readInt(line, i)
+	q, r := 0, n
+	if i < len(line) && line[i] == ':' {
+		q, i = readInt(line, i+1)
+		if i < len(line) && line[i] == ':' {
+			r, i = readInt(line, i+1)
+		}
+	}
+	if q == 0 {
+		switch n {
+		case 2, 4, 8:
+			q = 3
+		case 3, 6:
+			q = 2
+		default:
+			q = 2
+			if m := p.tune.Meter; m.Beats%3 == 0 && m.Beats > 3 {
+				q = 3
+			}
+		}
+	}
+	if n > 0 && r > 0 {
+		p.tupletLeft, p.tupletRatio = r, float64(q)/float64(n)
+	}
+	return i
+}
+
+// Parses a broken rhythm (">" makes the previous note longer and the next one shorter, "<" does the opposite).
+func (p *abcParser) brokenRhythm(line string, i int) (next int) {
+	c, n := line[i], 0
+	for ; i < len(line) && line[i] == c; i++ {
+		n++
+	}
+	short := 1 / float64(int(1)<<n)
+	long := 2 - short
+	if c == '<' {
+		long, short = short, long
+	}
+	if p.lastNote >= 0 {
+		p.items[p.lastNote].beats *= long
+	}
+	p.nextMultiplier = short
+	return i
+}
+
+// Plays out repeats and computes when each note starts and stops.
+func (p *abcParser) schedule() {
+	var played []abcItem
+	start, second := 0, false
+	for i := 0; i < len(p.items); i++ {
+		switch it := p.items[i]; it.kind {
+		case abcNotes:
+			played = append(played, it)
+		case abcRepeatStart:
+			start, second = i+1, false
+		case abcRepeatEnd:
+			if !second {
+				i, second = start-1, true
+			} else {
+				start, second = i+1, false
+			}
+		case abcEnding:
+			if !second {
+				continue
+			}
+			if it.ending != 1 {
+				second = false
+				continue
+			}
+			// Skip the first ending when repeating.
+			for i++; i < len(p.items); i++ {
+				if k := p.items[i].kind; k == abcEnding && p.items[i].ending != 1 {
+					i--
+					break
+				} else if k == abcRepeatEnd {
+					start, second = i+1, false
+					break
+				}
+			}
+		}
+	}
+
+	bpm := p.tune.BPM
+	var beats float64
+	var ends []float64
+	tied := map[Note]int{} // Index of the notes tied to the next item
+	for _, it := range played {
+		next := map[Note]int{}
+		for _, n := range it.notes {
+			j, ok := tied[n]
+			if !ok {
+				j = len(p.tune.Notes)
+				p.tune.Notes = append(p.tune.Notes, NoteEvent{Note: n, Start: bpm.T(beats), Velocity: 0.75})
+				ends = append(ends, 0)
+			}
+			ends[j] = beats + it.beats
+			if it.tie {
+				next[n] = j
+			}
+		}
+		tied = next
+		beats += it.beats
+	}
+	for i := range p.tune.Notes {
+		p.tune.Notes[i].Duration = bpm.T(ends[i]) - p.tune.Notes[i].Start
+	}
+	p.tune.Length = bpm.T(beats)
+}
+
+// Parses a meter (ex: "6/8", "C" for 4/4, "C|" for 2/2, "2+3/8" for 5/8, "none" for free meter).
+func parseABCMeter(s string) (Meter, error) {
+	switch s {
+	case "", "none":
+		return Meter{}, nil
+	case "C":
+		return CommonTime, nil
+	case "C|":
+		return Meter{2, 2}, nil
+	}
+	num, den, ok := strings.Cut(s, "/")
+	unit, err := strconv.Atoi(strings.TrimSpace(den))
+	if !ok || err != nil || unit <= 0 {
+		return Meter{}, fmt.Errorf("invalid meter %q", s)
+	}
+	beats := 0
+	for _, v := range strings.Split(strings.Trim(num, "()"), "+") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return Meter{}, fmt.Errorf("invalid meter %q", s)
+		}
+		beats += n
+	}
+	return Meter{beats, unit}, nil
+}
+
+// Parses a tempo (ex: "1/4=120", "3/8=60", "\"Allegro\" 1/4=140", or "120" unit notes per minute),
+// text-only tempos (ex: "\"Allegro\"") give 0.
+func parseABCTempo(s string, unit float64) (BPM, error) {
+	for {
+		start := strings.IndexByte(s, '"')
+		end := strings.IndexByte(s[start+1:], '"')
+		if start < 0 || end < 0 {
+			break
+		}
+		s = s[:start] + s[start+1+end+1:]
+	}
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	beats, value, ok := strings.Cut(s, "=")
+	if !ok {
+		value, beats = beats, ""
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid tempo %q", s)
+	}
+	quarters := unit
+	if fields := strings.Fields(beats); len(fields) > 0 {
+		quarters = 0
+		for _, f := range fields {
+			v, err := parseABCFraction(f)
+			if err != nil {
+				return 0, fmt.Errorf("invalid tempo %q", s)
+			}
+			quarters += 4 * v
+		}
+	}
+	return BPM(n * quarters), nil
+}
+
+// Parses a key (ex: "G", "F#m", "Bb mix", "D dorian", "none"), returning its key signature
+// (the accidental of each letter, from C).
+func parseABCKey(s string) (key Key, signature [7]int, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || fields[0] == "none" {
+		return Key{C4, Major}, signature, nil
+	}
+	tonic := fields[0]
+	letter := strings.IndexByte(abcLetters, tonic[0])
+	if letter < 0 {
+		return key, signature, fmt.Errorf("unsupported key %q", s)
+	}
+	semitones := letterSemitones[rune(tonic[0])]
+	mode := tonic[1:]
+	if strings.HasPrefix(mode, "#") {
+		semitones, mode = semitones+1, mode[1:]
+	} else if strings.HasPrefix(mode, "b") {
+		semitones, mode = semitones-1, mode[1:]
+	}
+	if mode == "" && len(fields) > 1 && !strings.Contains(fields[1], "=") {
+		mode = fields[1]
+	}
+	scale, ok := abcModes[strings.ToLower(mode[:min(3, len(mode))])]
+	if strings.ToLower(mode) == "m" {
+		scale, ok = Aeolian, true
+	}
+	if !ok {
+		return key, signature, fmt.Errorf("unsupported mode %q", mode)
+	}
+	for i, interval := range scale {
+		l := (letter + i) % 7
+		v := ((semitones+interval-letterSemitones[rune(abcLetters[l])])%12 + 12) % 12
+		if v > 6 {
+			v -= 12
+		}
+		signature[l] = v
+	}
+	return Key{C4 + Note(semitones), scale}, signature, nil
+}
+
+var abcModes = map[string]Scale{
+	"": Major, "maj": Major, "ion": Ionian, "min": Aeolian, "aeo": Aeolian,
+	"dor": Dorian, "phr": Phrygian, "lyd": Lydian, "mix": Mixolydian, "loc": Locrian,
+}
+
+// Parses a fraction (ex: "1/8", "3/4", "1").
+func parseABCFraction(s string) (float64, error) {
+	num, den, ok := strings.Cut(strings.TrimSpace(s), "/")
+	a, err := strconv.Atoi(num)
+	if err != nil {
+		return 0, err
+	}
+	b := 1
+	if ok {
+		if b, err = strconv.Atoi(den); err != nil || b == 0 {
+			return 0, fmt.Errorf("invalid fraction %q", s)
+		}
+	}
+	return float64(a) / float64(b), nil
+}
+
+// Reads a note length (ex: "2", "/2", "/", "//", "3/2") as a multiple of the unit note length.
+func readLength(s string, i int) (mult float64, next int) {
+	num, den := 1, 1
+	if i < len(s) && isDigit(s[i]) {
+		num, i = readInt(s, i)
+	}
+	for i < len(s) && s[i] == '/' {
+		i++
+		if i < len(s) && isDigit(s[i]) {
+			var d int
+			d, i = readInt(s, i)
+			den *= max(d, 1)
+		} else {
+			den *= 2
+		}
+	}
+	return float64(num) / float64(den), i
+}
+
+func readInt(s string, i int) (n, next int) {
+	for ; i < len(s) && isDigit(s[i]); i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, i
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,54 @@
+package music
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Order in which the notes of a chord are played by an arpeggio,
+// it receives the notes from the lowest to the highest.
+type ArpPattern func(notes []Note) []Note
+
+var (
+	ArpUp   ArpPattern = func(notes []Note) []Note { return notes }
+	ArpDown ArpPattern = func(notes []Note) []Note { return reversed(notes) }
+	// Up and then down, without repeating the highest and lowest notes (ex: C E G E).
+	ArpUpDown ArpPattern = func(notes []Note) []Note {
+		if len(notes) < 3 {
+			return notes
+		}
+		return append(slices.Clone(notes), reversed(notes[1:len(notes)-1])...)
+	}
+)
+
+func reversed(notes []Note) []Note {
+	notes = slices.Clone(notes)
+	slices.Reverse(notes)
+	return notes
+}
+
+// Plays the notes in a random order (the same for a given seed).
+func ArpRandom(seed uint64) ArpPattern {
+	return func(notes []Note) []Note {
+		r := rand.New(rand.NewPCG(seed, seed))
+		notes = slices.Clone(notes)
+		r.Shuffle(len(notes), func(i, j int) { notes[i], notes[j] = notes[j], notes[i] })
+		return notes
+	}
+}
+
+// Returns a sequence playing the notes of the chord one after the other (each for the given duration),
+// in the order defined by the pattern, using the given oscillator.
+//
+// Ex: music.Arpeggiate(chord, music.ArpUpDown, bpm.T(0.25), dsp.Sine)
+func Arpeggiate(c Chord, pattern ArpPattern, d time.Duration, osc dsp.Oscillator) dsp.FiniteSignal {
+	notes := pattern(c.Notes())
+	steps := make([]dsp.FiniteSignal, len(notes))
+	for i, n := range notes {
+		steps[i] = dsp.F(d, osc(n))
+	}
+	return dsp.F(time.Duration(len(steps))*d, dsp.Sequence(steps...))
+}
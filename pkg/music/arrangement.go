@@ -0,0 +1,112 @@
+package music
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Song structure: sections played one after the other, each playing some of the tracks of the mixer.
+// Tracks are played from the start of each section (and of each repetition),
+// they're silent during the sections they aren't part of, while buses (ex: reverb tails) keep playing.
+//
+//	intro := music.Section{Name: "intro", Bars: 4, Solo: []string{"pad"}}
+//	verse := music.Section{Name: "verse", Bars: 8, Mute: []string{"lead"}}
+//	chorus := music.Section{Name: "chorus", Bars: 8, Repeat: 2}
+//	song := music.Arrangement{BPM: 120, Mixer: mix, Sections: []music.Section{intro, verse, chorus, verse, chorus}}
+//	master := song.Master()
+type Arrangement struct {
+	BPM      BPM
+	Meter    Meter     // Defaults to 4/4
+	Mixer    dsp.Mixer // Tracks (and buses) of the song
+	Sections []Section // In the order they're played
+}
+
+type Section struct {
+	Name   string
+	Bars   int
+	Repeat int      // Number of times the section is played, defaults to 1
+	Mute   []string // Names of the tracks that aren't played
+	Solo   []string // When set, names of the only tracks played
+}
+
+func (s Section) plays(track string) bool {
+	if len(s.Solo) > 0 {
+		return slices.Contains(s.Solo, track)
+	}
+	return !slices.Contains(s.Mute, track)
+}
+
+func (s Section) repeat() int { return max(s.Repeat, 1) }
+
+// Returns the duration of the song.
+func (a Arrangement) Length() (d time.Duration) {
+	for _, s := range a.Sections {
+		d += time.Duration(s.repeat()) * a.bars(s.Bars)
+	}
+	return d
+}
+
+// Returns the start of the first section with the given name (ex: to render only the chorus).
+func (a Arrangement) Start(name string) (at time.Duration, ok bool) {
+	for _, s := range a.Sections {
+		if s.Name == name {
+			return at, true
+		}
+		at += time.Duration(s.repeat()) * a.bars(s.Bars)
+	}
+	return 0, false
+}
+
+// Returns the mix of the song. It panics if a section mutes or solos an unknown track.
+func (a Arrangement) Master() dsp.Stereo {
+	names := make([]string, len(a.Mixer.Tracks))
+	for i, t := range a.Mixer.Tracks {
+		names[i] = t.Name
+	}
+	for _, s := range a.Sections {
+		for _, name := range append(slices.Clone(s.Mute), s.Solo...) {
+			if !slices.Contains(names, name) {
+				panic(fmt.Errorf("section %q refers to unknown track %q", s.Name, name))
+			}
+		}
+	}
+
+	// Start and length of each repetition of each section.
+	type part struct {
+		start, length time.Duration
+		section       Section
+	}
+	var parts []part
+	var at time.Duration
+	for _, s := range a.Sections {
+		for range s.repeat() {
+			parts = append(parts, part{at, a.bars(s.Bars), s})
+			at += a.bars(s.Bars)
+		}
+	}
+
+	mix := a.Mixer
+	mix.Tracks = slices.Clone(mix.Tracks)
+	for i, t := range mix.Tracks {
+		in := t.In
+		mix.Tracks[i].In = dsp.SignalFunc(func(x time.Duration) (y float64) {
+			j, found := slices.BinarySearchFunc(parts, x, func(p part, x time.Duration) int { return cmp.Compare(p.start, x) })
+			if !found {
+				j--
+			}
+			if j < 0 || j >= len(parts) || x >= parts[j].start+parts[j].length || !parts[j].section.plays(t.Name) {
+				return 0
+			}
+			return in.At(x - parts[j].start)
+		})
+	}
+	return mix.Master()
+}
+
+func (a Arrangement) bars(n int) time.Duration {
+	return time.Duration(n) * Transport{BPM: a.BPM, Meter: a.Meter}.Bar()
+}
@@ -0,0 +1,117 @@
+package music
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Chord quality, defined by the intervals (in semitones) of its notes from the root.
+type ChordQuality []int
+
+var (
+	// Triads
+	MajorTriad = ChordQuality{0, 4, 7}
+	MinorTriad = ChordQuality{0, 3, 7}
+	Diminished = ChordQuality{0, 3, 6}
+	Augmented  = ChordQuality{0, 4, 8}
+	Sus2       = ChordQuality{0, 2, 7}
+	Sus4       = ChordQuality{0, 5, 7}
+
+	// Sevenths
+	Major7          = ChordQuality{0, 4, 7, 11}
+	Minor7          = ChordQuality{0, 3, 7, 10}
+	Dominant7       = ChordQuality{0, 4, 7, 10}
+	MinorMajor7     = ChordQuality{0, 3, 7, 11}
+	HalfDiminished7 = ChordQuality{0, 3, 6, 10}
+	Diminished7     = ChordQuality{0, 3, 6, 9}
+	Dominant7Sus4   = ChordQuality{0, 5, 7, 10}
+
+	// Extensions
+	Add9       = ChordQuality{0, 4, 7, 14}
+	Major9     = ChordQuality{0, 4, 7, 11, 14}
+	Minor9     = ChordQuality{0, 3, 7, 10, 14}
+	Dominant9  = ChordQuality{0, 4, 7, 10, 14}
+	Minor11    = ChordQuality{0, 3, 7, 10, 14, 17}
+	Dominant11 = ChordQuality{0, 4, 7, 10, 14, 17}
+	Major13    = ChordQuality{0, 4, 7, 11, 14, 21} // The 11th is usually omitted
+	Minor13    = ChordQuality{0, 3, 7, 10, 14, 17, 21}
+	Dominant13 = ChordQuality{0, 4, 7, 10, 14, 21} // The 11th is usually omitted
+)
+
+// Chord symbol suffixes of the known chord qualities (in the order they're recognized by Identify).
+var chordSuffixes = []struct {
+	quality ChordQuality
+	suffix  string
+}{
+	{MajorTriad, ""}, {MinorTriad, "m"}, {Diminished, "dim"}, {Augmented, "aug"}, {Sus2, "sus2"}, {Sus4, "sus4"},
+	{Major7, "maj7"}, {Minor7, "m7"}, {Dominant7, "7"}, {MinorMajor7, "m(maj7)"},
+	{HalfDiminished7, "m7b5"}, {Diminished7, "dim7"}, {Dominant7Sus4, "7sus4"},
+	{Add9, "add9"}, {Major9, "maj9"}, {Minor9, "m9"}, {Dominant9, "9"}, {Minor11, "m11"}, {Dominant11, "11"},
+	{Major13, "maj13"}, {Minor13, "m13"}, {Dominant13, "13"},
+}
+
+type Chord struct {
+	Root      Note
+	Quality   ChordQuality
+	Inversion int // Number of notes (from the bottom) moved up an octave, 0 is the root position
+}
+
+// Returns the notes of the chord, from the lowest to the highest.
+func (c Chord) Notes() []Note {
+	notes := make([]Note, len(c.Quality))
+	for i, v := range c.Quality {
+		notes[i] = c.Root + Note(v)
+	}
+	for i := 0; i < c.Inversion; i++ {
+		notes = append(notes[1:], notes[0]+12*Note(1+(notes[len(notes)-1]-notes[0])/12))
+	}
+	return notes
+}
+
+// Returns the frequencies (in Hertz) of the notes of the chord.
+func (c Chord) Frequencies() []float64 {
+	notes := c.Notes()
+	freqs := make([]float64, len(notes))
+	for i, n := range notes {
+		freqs[i] = n.Hz()
+	}
+	return freqs
+}
+
+// Returns a signal playing all the notes of the chord at once with the given oscillator.
+//
+// Ex: music.Chord{Root: music.C4, Quality: music.MajorTriad}.Play(dsp.Sine)
+func (c Chord) Play(osc dsp.Oscillator) dsp.Signal {
+	notes := c.Notes()
+	signals := make([]dsp.Signal, len(notes))
+	for i, n := range notes {
+		signals[i] = osc(n)
+	}
+	return dsp.Combine(signals...)
+}
+
+// Returns the chord symbol (ex: "Cmaj7", "Gbm", "C/E" for inverted chords).
+// Unknown qualities are written as their intervals (ex: "C(0,4,7,9)").
+func (c Chord) String() string {
+	name, known := noteNames[((int(c.Root)%12)+12)%12], false
+	for _, s := range chordSuffixes {
+		if slices.Equal(s.quality, c.Quality) {
+			name, known = name+s.suffix, true
+			break
+		}
+	}
+	if !known {
+		intervals := make([]string, len(c.Quality))
+		for i, v := range c.Quality {
+			intervals[i] = strconv.Itoa(v)
+		}
+		name += "(" + strings.Join(intervals, ",") + ")"
+	}
+	if notes := c.Notes(); c.Inversion > 0 && len(notes) > 0 {
+		name += "/" + noteNames[((int(notes[0])%12)+12)%12]
+	}
+	return name
+}
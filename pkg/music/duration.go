@@ -0,0 +1,39 @@
+package music
+
+import "time"
+
+// Rhythmic duration written like in notation, as a fraction of a whole note.
+// Durations can be added together (ex: Half + Eighth for a half note tied to an eighth note).
+//
+// Ex: music.Quarter.Dotted().T(120), music.Eighth.Triplet().T(bpm)
+type Dur float64
+
+const (
+	Whole        Dur = 1
+	Half         Dur = 1.0 / 2
+	Quarter      Dur = 1.0 / 4
+	Eighth       Dur = 1.0 / 8
+	Sixteenth    Dur = 1.0 / 16
+	ThirtySecond Dur = 1.0 / 32
+)
+
+// Returns the duration one and a half times longer (ex: a dotted quarter note lasts three eighth notes).
+func (d Dur) Dotted() Dur { return d * 3 / 2 }
+
+// Returns the duration with two dots (one and three quarter times longer).
+func (d Dur) DoubleDotted() Dur { return d * 7 / 4 }
+
+// Returns the duration of each note of a tuplet: n notes played in the time of m (ex: Tuplet(3, 2) for triplets).
+func (d Dur) Tuplet(n, m int) Dur { return d * Dur(m) / Dur(n) }
+
+// Returns the duration of each note of a triplet (three notes in the time of two).
+func (d Dur) Triplet() Dur { return d.Tuplet(3, 2) }
+
+// Returns the number of quarter notes (the beats of BPM).
+func (d Dur) Quarters() float64 { return float64(d) * 4 }
+
+// Returns the number of beats in the unit of the meter (ex: a quarter note is two beats in 6/8).
+func (d Dur) Beats(m Meter) float64 { return float64(d) * float64(m.Unit) }
+
+// Returns how long the duration lasts at the given tempo.
+func (d Dur) T(bpm BPM) time.Duration { return bpm.T(d.Quarters()) }
@@ -0,0 +1,108 @@
+package music
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Note played at a given time, for a given duration.
+type NoteEvent struct {
+	Note     Note
+	Start    time.Duration
+	Duration time.Duration
+	Velocity float64    // From 0 to 1
+	Bend     dsp.Signal // Pitch bend (in semitones, see dsp.Bend) from the start of the note, nil means none
+}
+
+func (e NoteEvent) End() time.Duration { return e.Start + e.Duration }
+
+// Notes of a piece, like a piano roll: the representation shared by importers (ABC, melodies, generators)
+// and exporters (MIDI files) or renderers (see Signal).
+// Editing methods return a new piano roll and leave the original one unchanged.
+type PianoRoll []NoteEvent
+
+// Returns the end of the last note.
+func (r PianoRoll) Length() (d time.Duration) {
+	for _, e := range r {
+		d = max(d, e.End())
+	}
+	return d
+}
+
+// Returns the notes sorted by start (and by note for notes starting at the same time).
+func (r PianoRoll) Sorted() PianoRoll {
+	r = slices.Clone(r)
+	slices.SortStableFunc(r, func(a, b NoteEvent) int {
+		return cmp.Or(cmp.Compare(a.Start, b.Start), cmp.Compare(a.Note, b.Note))
+	})
+	return r
+}
+
+// Moves note starts towards the closest multiple of the grid (ex: bpm.T(0.25) for 16th notes).
+// The strength goes from 0 (unchanged) to 1 (exactly on the grid), durations are kept.
+func (r PianoRoll) Quantize(grid time.Duration, strength float64) PianoRoll {
+	r = slices.Clone(r)
+	if grid <= 0 {
+		return r
+	}
+	for i, e := range r {
+		target := time.Duration(math.Round(float64(e.Start)/float64(grid))) * grid
+		r[i].Start = e.Start + time.Duration(strength*float64(target-e.Start))
+	}
+	return r
+}
+
+func (r PianoRoll) Transpose(semitones int) PianoRoll {
+	r = slices.Clone(r)
+	for i := range r {
+		r[i].Note += Note(semitones)
+	}
+	return r
+}
+
+// Transposes the notes from one key to the same degrees of another key (see Key.Transpose),
+// ex: to play a melody in C major in A minor.
+func (r PianoRoll) TransposeKey(from, to Key) PianoRoll {
+	r = slices.Clone(r)
+	for i, e := range r {
+		r[i].Note = from.Transpose(e.Note, to)
+	}
+	return r
+}
+
+// Moves all the notes later (or earlier with a negative offset), notes moved before 0 are dropped.
+func (r PianoRoll) Shift(by time.Duration) PianoRoll {
+	var shifted PianoRoll
+	for _, e := range r {
+		if e.Start += by; e.Start >= 0 {
+			shifted = append(shifted, e)
+		}
+	}
+	return shifted
+}
+
+// Returns the notes sounding between from and to, cut to fit and moved so that from is the new start.
+func (r PianoRoll) Slice(from, to time.Duration) PianoRoll {
+	var sliced PianoRoll
+	for _, e := range r {
+		start, end := max(e.Start, from), min(e.End(), to)
+		if start >= end {
+			continue
+		}
+		if bend, cut := e.Bend, start-e.Start; bend != nil && cut > 0 {
+			e.Bend = dsp.SignalFunc(func(x time.Duration) (y float64) { return bend.At(x + cut) })
+		}
+		e.Start, e.Duration = start-from, end-start
+		sliced = append(sliced, e)
+	}
+	return sliced
+}
+
+// Returns the notes played by a synth using the given instrument, lasting until the end of the last note.
+func (r PianoRoll) Signal(instrument Instrument) dsp.FiniteSignal {
+	return playNotes(r, r.Length(), instrument)
+}
@@ -0,0 +1,71 @@
+package music
+
+// Returns the key n steps around the circle of fifths, with the same scale:
+// a fifth above for each step clockwise (n > 0, towards the sharps), a fifth below for each step counterclockwise (n < 0).
+// The tonic stays within the octave starting on the tonic of k.
+//
+// Ex: Key{C4, Major}.Fifths(2) is D4 major, Key{C4, Major}.Fifths(-1) is F4 major
+func (k Key) Fifths(n int) Key {
+	return Key{k.Tonic + Note(((n*int(PerfectFifth))%12+12)%12), k.Scale}
+}
+
+// Returns the 12 keys of the circle of fifths, starting on k and going clockwise.
+func CircleOfFifths(k Key) []Key { return k.Chain(11) }
+
+// Returns the key followed by the given number of keys going around the circle of fifths:
+// a chain of dominants for n > 0 (ex: C G D A), a chain of subdominants for n < 0 (ex: C F Bb Eb).
+func (k Key) Chain(n int) []Key {
+	step := 1
+	if n < 0 {
+		n, step = -n, -1
+	}
+	keys := make([]Key, n+1)
+	for i := range keys {
+		keys[i] = k.Fifths(i * step)
+	}
+	return keys
+}
+
+// Returns the number of steps between two keys on the circle of fifths (from 0 to 6),
+// which is the number of accidentals that differ between their signatures (ex: 0 for relative keys).
+// Keys without a signature are placed on the circle like the major key with the same tonic.
+func (k Key) Distance(to Key) int {
+	d := (((to.fifths() - k.fifths()) % 12) + 12) % 12
+	return min(d, 12-d)
+}
+
+// Returns the keys closely related to k: the keys whose signature differs by at most one accidental
+// (the dominant, the subdominant, the relative key, and the relatives of the dominant and subdominant).
+//
+// Ex: for C major, G major, F major, A minor, E minor and D minor
+func (k Key) Related() []Key {
+	dominant, subdominant := k.Fifths(1), k.Fifths(-1)
+	return []Key{dominant, subdominant, k.Relative(), dominant.Relative(), subdominant.Relative()}
+}
+
+// Suggests a modulation from one key to another: the keys to go through, one step at a time around the circle of fifths
+// (in the mode of the first key), until reaching a key whose signature is close enough to move to the last key.
+// The path starts with from and ends with to.
+//
+// Ex: music.ModulationPath(Key{C4, Major}, Key{C4, NaturalMinor}) goes through F major and Bb major
+func ModulationPath(from, to Key) []Key {
+	path := []Key{from}
+	d := (((to.fifths() - from.fifths()) % 12) + 12) % 12
+	step := 1
+	if d > 6 {
+		d, step = 12-d, -1
+	}
+	for i := 1; i < d; i++ {
+		path = append(path, from.Fifths(i*step))
+	}
+	return append(path, to)
+}
+
+// Returns the position of the key on the circle of fifths (the number of sharps, or minus the number of flats).
+func (k Key) fifths() int {
+	if accidentals, ok := k.Signature(); ok {
+		return accidentals
+	}
+	accidentals, _ := Key{k.Tonic, Major}.Signature()
+	return accidentals
+}
@@ -0,0 +1,25 @@
+package music
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Bounded random variations making sequenced notes sound less mechanical.
+type Humanize struct {
+	Timing   time.Duration // Maximum offset of note starts (earlier or later)
+	Velocity float64       // Maximum change of velocity (ex: 0.1)
+}
+
+// Returns the notes with random offsets applied to their start and velocity (the same for a given seed).
+// Notes keep their duration, they don't start before 0 and velocities stay between 0 and 1.
+func (h Humanize) Apply(notes PianoRoll, seed uint64) PianoRoll {
+	r := rand.New(rand.NewPCG(seed, seed))
+	out := make(PianoRoll, len(notes))
+	for i, n := range notes {
+		n.Start = max(0, n.Start+time.Duration((2*r.Float64()-1)*float64(h.Timing)))
+		n.Velocity = max(0, min(1, n.Velocity+(2*r.Float64()-1)*h.Velocity))
+		out[i] = n
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package music
+
+import (
+	"slices"
+)
+
+// Names the chord made of the given notes (in any order and octave, doubled notes are ignored):
+// its root, its quality (among the known chord qualities, including extensions such as 9th chords),
+// and its inversion (from the lowest note). Voicings without the fifth are recognized too (ex: C E Bb is C7).
+// When several chords match, the one whose root is the lowest note is preferred.
+// It returns false if the notes don't make a known chord.
+//
+// Ex: music.Identify(music.E3, music.G3, music.C4) is C major, first inversion ("C/E")
+func Identify(notes ...Note) (c Chord, ok bool) {
+	if len(notes) == 0 {
+		return c, false
+	}
+	bass := slices.Min(notes)
+	set := pitchClasses(notes)
+	// Candidate roots, the bass first.
+	roots := []int{pitchClass(bass)}
+	for _, pc := range set {
+		if pc != roots[0] {
+			roots = append(roots, pc)
+		}
+	}
+	for _, omitFifth := range []bool{false, true} {
+		for _, root := range roots {
+			for _, s := range chordSuffixes {
+				quality := s.quality
+				if omitFifth {
+					if len(quality) < 4 || !slices.Contains(quality, int(PerfectFifth)) {
+						continue
+					}
+					quality = slices.DeleteFunc(slices.Clone(quality), func(v int) bool { return v == int(PerfectFifth) })
+				}
+				chord := make([]Note, len(quality))
+				for i, v := range quality {
+					chord[i] = Note(root + v)
+				}
+				if !slices.Equal(pitchClasses(chord), set) {
+					continue
+				}
+				c = Chord{Root: bass - Note(((pitchClass(bass)-root)%12+12)%12), Quality: s.quality}
+				c.Inversion = slices.IndexFunc(c.Quality, func(v int) bool { return pitchClass(c.Root+Note(v)) == pitchClass(bass) })
+				return c, true
+			}
+		}
+	}
+	return Chord{}, false
+}
+
+func pitchClass(n Note) int { return ((int(n) % 12) + 12) % 12 }
+
+// Returns the sorted pitch classes (from 0 for C to 11 for B) of the notes, without duplicates.
+func pitchClasses(notes []Note) []int {
+	set := make([]int, len(notes))
+	for i, n := range notes {
+		set[i] = pitchClass(n)
+	}
+	slices.Sort(set)
+	return slices.Compact(set)
+}
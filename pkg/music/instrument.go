@@ -0,0 +1,32 @@
+package music
+
+import (
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Returns the signal of a single note played at a given velocity (from 0 to 1).
+// The signal starts at 0 when the note starts.
+type Instrument func(n Note, velocity float64) dsp.Signal
+
+// Instrument playing the given oscillator, with an amplitude proportional to the velocity.
+func Oscillator(osc dsp.Oscillator) Instrument {
+	return func(n Note, velocity float64) dsp.Signal {
+		return dsp.Amplify(osc(n), dsp.Constant(velocity))
+	}
+}
+
+// Instrument playing plucked strings (see dsp.Pluck) at the given sample rate.
+func Pluck(rate int, decay time.Duration) Instrument {
+	return func(n Note, velocity float64) dsp.Signal {
+		return dsp.Amplify(dsp.Pluck(n.Hz(), rate, decay), dsp.Constant(velocity))
+	}
+}
+
+// Instrument playing a recorded sound (see dsp.Sampler), with an amplitude proportional to the velocity.
+func Sampler(s dsp.Sampler) Instrument {
+	return func(n Note, velocity float64) dsp.Signal {
+		return dsp.Amplify(s.Play(n.Hz()), dsp.Constant(velocity))
+	}
+}
@@ -0,0 +1,93 @@
+package music
+
+import (
+	"strconv"
+)
+
+// Distance between two notes (in semitones), negative for descending intervals.
+//
+// Ex: music.C4.Add(music.PerfectFifth) is G4
+type Interval int
+
+const (
+	Unison Interval = iota
+	MinorSecond
+	MajorSecond
+	MinorThird
+	MajorThird
+	PerfectFourth
+	Tritone // Augmented fourth or diminished fifth
+	PerfectFifth
+	MinorSixth
+	MajorSixth
+	MinorSeventh
+	MajorSeventh
+	Octave
+
+	// Compound intervals (more than an octave)
+	MinorNinth        = MinorSecond + Octave
+	MajorNinth        = MajorSecond + Octave
+	MinorTenth        = MinorThird + Octave
+	MajorTenth        = MajorThird + Octave
+	PerfectEleventh   = PerfectFourth + Octave
+	AugmentedEleventh = Tritone + Octave
+	PerfectTwelfth    = PerfectFifth + Octave
+	MinorThirteenth   = MinorSixth + Octave
+	MajorThirteenth   = MajorSixth + Octave
+)
+
+// Returns the note the interval above (or below, for descending intervals) n.
+func (n Note) Add(i Interval) Note { return n + Note(i) }
+
+// Returns the interval from a to b (descending when b is lower than a).
+func IntervalBetween(a, b Note) Interval { return Interval(b - a) }
+
+func (i Interval) Semitones() int { return int(i) }
+
+// Reports whether the interval spans more than an octave.
+func (i Interval) IsCompound() bool { return i > Octave || i < -Octave }
+
+// Returns the interval reduced to at most an octave, keeping its direction (ex: a major ninth becomes a major second).
+func (i Interval) Simple() Interval {
+	switch {
+	case i < 0:
+		return -(-i).Simple()
+	case i > 0 && i%Octave == 0:
+		return Octave
+	default:
+		return i % Octave
+	}
+}
+
+// Returns the interval extended by a number of octaves, keeping its direction (ex: MajorSecond.Compound(1) is a major ninth).
+func (i Interval) Compound(octaves int) Interval {
+	if i < 0 {
+		return -(-i).Compound(octaves)
+	}
+	return i + Interval(octaves)*Octave
+}
+
+// Returns the interval that completes it to an octave (ex: a major third inverts to a minor sixth),
+// compound intervals are reduced to simple intervals first.
+func (i Interval) Invert() Interval {
+	if i < 0 {
+		return -(-i).Invert()
+	}
+	return Octave - i.Simple()
+}
+
+var intervalNames = [12]struct {
+	quality string
+	number  int
+}{
+	{"P", 1}, {"m", 2}, {"M", 2}, {"m", 3}, {"M", 3}, {"P", 4}, {"A", 4}, {"P", 5}, {"m", 6}, {"M", 6}, {"m", 7}, {"M", 7},
+}
+
+// Returns the interval name in short notation (ex: "m3", "P5", "M9", "A4" for the tritone, "-P8" for an octave down).
+func (i Interval) String() string {
+	if i < 0 {
+		return "-" + (-i).String()
+	}
+	name := intervalNames[i%Octave]
+	return name.quality + strconv.Itoa(name.number+7*int(i/Octave))
+}
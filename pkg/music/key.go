@@ -0,0 +1,121 @@
+package music
+
+import "slices"
+
+// Musical key, made of a tonic and a scale (ex: Key{C4, Major}, Key{A3, NaturalMinor}).
+type Key struct {
+	Tonic Note
+	Scale Scale
+}
+
+// Returns the note on the n-th degree (starting at 1) of the key.
+func (k Key) Degree(n int) Note { return k.Tonic + Note(k.Scale.Degree(n)) }
+
+// Returns the diatonic chord built on the n-th degree (starting at 1) of the key,
+// made of the given number of notes stacked in thirds (3 for triads, 4 for seventh chords, etc.).
+//
+// Ex: Key{C4, Major}.Chord(5, 4) is G4 dominant seventh
+func (k Key) Chord(degree, notes int) Chord {
+	root := k.Scale.Degree(degree)
+	quality := make(ChordQuality, notes)
+	for i := range quality {
+		quality[i] = k.Scale.Degree(degree+2*i) - root
+	}
+	return Chord{Root: k.Tonic + Note(root), Quality: quality}
+}
+
+// Reports whether the key is minor (its third degree is a minor third above the tonic).
+func (k Key) IsMinor() bool {
+	return k.Scale.Contains(0, Note(MinorThird)) && !k.Scale.Contains(0, Note(MajorThird))
+}
+
+// Returns the number of sharps (positive) or flats (negative) of the key signature, and whether the key has one.
+// Keys on modes of the major scale (and on scales with fewer notes, ex: pentatonic) have a signature,
+// harmonic and melodic minor keys use the signature of the natural minor key.
+// Six accidentals are written as flats (ex: Gb major is -6).
+//
+// Ex: Key{E4, Major}.Signature() is 4 (F#, C#, G#, D#)
+func (k Key) Signature() (accidentals int, ok bool) {
+	parent, ok := k.parent()
+	if !ok {
+		return 0, false
+	}
+	// Number of fifths from C to the tonic of the parent major scale.
+	accidentals = ((int(parent)%12 + 12) % 12) * 7 % 12
+	if accidentals > 5 {
+		accidentals -= 12
+	}
+	return accidentals, true
+}
+
+// Returns the tonic of the major scale the key is a mode of (below the tonic of the key, within an octave),
+// minor keys that aren't a mode of the major scale (ex: harmonic minor) use the parent of the natural minor key.
+func (k Key) parent() (tonic Note, ok bool) {
+	for _, mode := range []int{1, 6, 2, 3, 4, 5, 7} { // Major and minor first
+		candidate := Major.Mode(mode)
+		if !slices.ContainsFunc(k.Scale, func(v int) bool { return !slices.Contains(candidate, ((v%12)+12)%12) }) {
+			return k.Tonic - Note(Major.Degree(mode)), true
+		}
+	}
+	if k.IsMinor() {
+		return k.Tonic - Note(Major.Degree(6)), true
+	}
+	return 0, false
+}
+
+// Returns the key sharing the same signature with the other mode, with the closest tonic:
+// the relative major of minor keys (ex: C major for A minor, or for D Dorian),
+// and the relative minor of other keys (ex: A minor for C major, or for G Mixolydian).
+// Keys without a signature (see Signature) are treated as major keys (their relative minor is a minor third below).
+func (k Key) Relative() Key {
+	parent, ok := k.parent()
+	if !ok {
+		return Key{k.Tonic.Add(-MinorThird), NaturalMinor}
+	}
+	if k.IsMinor() {
+		return Key{closest(k.Tonic, parent), Major}
+	}
+	return Key{closest(k.Tonic, parent.Add(MajorSixth)), NaturalMinor}
+}
+
+// Returns the note with the same pitch class as n that is closest to the reference (at most a tritone above or below).
+func closest(reference, n Note) Note {
+	offset := ((int(n-reference))%12 + 12) % 12
+	if offset > 6 {
+		offset -= 12
+	}
+	return reference + Note(offset)
+}
+
+// Returns the key with the same tonic and the other mode (ex: C minor for C major).
+func (k Key) Parallel() Key {
+	if k.IsMinor() {
+		return Key{k.Tonic, Major}
+	}
+	return Key{k.Tonic, NaturalMinor}
+}
+
+// Returns the key a fifth above, with the same scale (ex: G major for C major).
+func (k Key) Dominant() Key { return Key{k.Tonic.Add(PerfectFifth), k.Scale} }
+
+// Returns the key a fourth above, with the same scale (ex: F major for C major).
+func (k Key) Subdominant() Key { return Key{k.Tonic.Add(PerfectFourth), k.Scale} }
+
+// Transposes a note of the key to the same degree of another key (ex: from C major to A minor, E4 becomes C4).
+// Notes outside of the key keep their distance to the degree below them.
+// Both keys should have scales with the same number of degrees.
+func (k Key) Transpose(n Note, to Key) Note {
+	distance := int(n - k.Tonic)
+	octave := distance / 12
+	if distance%12 < 0 {
+		octave--
+	}
+	pitch := distance - 12*octave
+	degree := 0
+	for i, v := range k.Scale {
+		if v <= pitch {
+			degree = i
+		}
+	}
+	return to.Tonic + Note(to.Scale.Degree(degree+1)+12*octave+pitch-k.Scale[degree])
+}
@@ -0,0 +1,130 @@
+package music
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Markov chain over notes: the weight of each note that can follow a given note.
+// It can be learned from examples (see LearnMarkov) or written by hand.
+//
+// Ex: mostly going up and down between C4 and E4, sometimes jumping to G4
+//
+//	music.Markov{
+//		music.C4: {music.E4: 3, music.G4: 1},
+//		music.E4: {music.C4: 1},
+//		music.G4: {music.C4: 1},
+//	}
+type Markov map[Note]map[Note]float64
+
+// Counts the transitions between consecutive notes of the example melodies.
+func LearnMarkov(melodies ...[]Note) Markov {
+	m := Markov{}
+	for _, melody := range melodies {
+		for i := 1; i < len(melody); i++ {
+			from, to := melody[i-1], melody[i]
+			if m[from] == nil {
+				m[from] = map[Note]float64{}
+			}
+			m[from][to]++
+		}
+	}
+	return m
+}
+
+// Options of Markov.Melody.
+type MarkovOptions struct {
+	Start  Note          // First note, defaults to a random note of the chain
+	Key    Key           // When it has a scale, notes outside of the key aren't played
+	Step   time.Duration // Length of each step of the rhythm grid
+	Steps  int           // Length of the melody (in steps)
+	Rhythm []bool        // Steps playing a note (repeating, ex: Euclid(5, 8, 0)), defaults to every step
+	Seed   uint64        // The same seed always generates the same melody
+}
+
+// Generates a melody by walking the chain: each note is drawn from the notes that followed the previous one.
+// When no note of the key can follow, the transitions are snapped to the key (see Key.Nearest),
+// and when the chain comes to a dead end, it starts over from a random note.
+func (m Markov) Melody(opts MarkovOptions) (notes PianoRoll) {
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	var current Note
+	started := false
+	for i := range opts.Steps {
+		if len(opts.Rhythm) > 0 && !opts.Rhythm[i%len(opts.Rhythm)] {
+			continue
+		}
+		switch {
+		case !started && opts.Start != 0:
+			current = opts.Start
+		case !started:
+			current = m.random(r, opts.Key)
+		default:
+			current = m.next(r, current, opts.Key)
+		}
+		started = true
+		notes = append(notes, NoteEvent{
+			Note:     current,
+			Start:    time.Duration(i) * opts.Step,
+			Duration: opts.Step,
+			Velocity: 0.75,
+		})
+	}
+	return notes
+}
+
+// Draws the note following n.
+func (m Markov) next(r *rand.Rand, n Note, key Key) Note {
+	weights := map[Note]float64{}
+	for to, w := range m[n] {
+		if key.Scale == nil || key.Scale.Contains(key.Tonic, to) {
+			weights[to] += w
+		}
+	}
+	if len(weights) == 0 {
+		for to, w := range m[n] {
+			weights[key.Nearest(to.Hz())] += w
+		}
+	}
+	if len(weights) == 0 {
+		return m.random(r, key)
+	}
+	return draw(r, weights)
+}
+
+// Draws one of the notes of the chain (in the key if possible), all with the same weight.
+func (m Markov) random(r *rand.Rand, key Key) Note {
+	weights := map[Note]float64{}
+	for n := range m {
+		if key.Scale == nil || key.Scale.Contains(key.Tonic, n) {
+			weights[n] = 1
+		}
+	}
+	if len(weights) == 0 {
+		for n := range m {
+			weights[n] = 1
+		}
+	}
+	if len(weights) == 0 {
+		return key.Tonic
+	}
+	return draw(r, weights)
+}
+
+// Draws a note with a probability proportional to its weight.
+func draw(r *rand.Rand, weights map[Note]float64) Note {
+	notes := make([]Note, 0, len(weights))
+	var total float64
+	for n, w := range weights {
+		notes = append(notes, n)
+		total += w
+	}
+	slices.Sort(notes) // Map iteration order is random, sorting keeps melodies reproducible
+	v := r.Float64() * total
+	for _, n := range notes {
+		if v -= weights[n]; v < 0 {
+			return n
+		}
+	}
+	return notes[len(notes)-1]
+}
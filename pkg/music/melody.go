@@ -0,0 +1,93 @@
+package music
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Parses a melody written as notes separated by spaces, each note being a note name (or "r" for a rest)
+// followed by its length: ":1" for a whole note, ":2" for a half note, ":4" for a quarter note, etc.
+// A dot makes the length one and a half times longer (ex: ":4." for a dotted quarter note).
+// When the length is omitted, the note is as long as the previous one (a quarter note for the first one).
+// Notes ending with ">" are accented (played at full velocity, others at 3/4).
+// Bar lines ("|") can be added for readability and are ignored.
+//
+// Ex: music.ParseSequence("c4:8 e4 g4:4 r:4 | a4:2")
+func ParseSequence(s string) (seq Sequence, err error) {
+	d := Quarter
+	for _, token := range strings.Fields(s) {
+		if token == "|" {
+			continue
+		}
+		velocity := 0.75
+		if rest, ok := strings.CutSuffix(token, ">"); ok {
+			token, velocity = rest, 1
+		}
+		name, value, hasLength := strings.Cut(token, ":")
+		if hasLength {
+			d, err = parseNoteLength(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid melody note %q: %w", token, err)
+			}
+		}
+		if name == "r" || name == "R" {
+			seq = append(seq, Rest(d))
+			continue
+		}
+		n, err := Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid melody note %q: %w", token, err)
+		}
+		seq = append(seq, Element{Notes: []Note{n}, Dur: d, Velocity: velocity})
+	}
+	return seq, nil
+}
+
+// Parses a melody (see ParseSequence) and returns the notes played (timed with the given tempo) and the length of the melody.
+//
+// Ex: music.ParseMelody("c4:8 e4 g4:4 r:4 | a4:2", 120)
+func ParseMelody(s string, bpm BPM) (notes PianoRoll, length time.Duration, err error) {
+	seq, err := ParseSequence(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return seq.PianoRoll(bpm), seq.Length().T(bpm), nil
+}
+
+// Parses a note length written as a fraction of a whole note (ex: "8", "4.").
+func parseNoteLength(s string) (Dur, error) {
+	dotted := strings.HasSuffix(s, ".")
+	v, err := strconv.Atoi(strings.TrimSuffix(s, "."))
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+	d := Whole / Dur(v)
+	if dotted {
+		d = d.Dotted()
+	}
+	return d, nil
+}
+
+// Compiles a melody (see ParseMelody) into a signal played by a synth using the given instrument.
+//
+// Ex: music.Melody("c4:8 e4:8 g4:4 r:4 | a4:2", 120, music.Oscillator(dsp.Triangle))
+func Melody(s string, bpm BPM, instrument Instrument) (dsp.FiniteSignal, error) {
+	notes, length, err := ParseMelody(s, bpm)
+	if err != nil {
+		return dsp.FiniteSignal{}, err
+	}
+	return playNotes(notes, length, instrument), nil
+}
+
+// Returns a synth playing the notes with the given instrument, lasting length.
+func playNotes(notes PianoRoll, length time.Duration, instrument Instrument) dsp.FiniteSignal {
+	synth := &Synth{Instrument: instrument}
+	for _, n := range notes {
+		synth.Play(n)
+	}
+	return dsp.F(length, synth)
+}
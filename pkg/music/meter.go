@@ -0,0 +1,56 @@
+package music
+
+import (
+	"fmt"
+	"time"
+)
+
+// Time signature (ex: Meter{7, 8} is 7/8).
+type Meter struct {
+	Beats int // Number of beats per bar
+	Unit  int // Note value of each beat (ex: 4 for quarter notes, 8 for eighth notes)
+}
+
+var CommonTime = Meter{4, 4}
+
+// Returns the number of quarter notes in a bar.
+func (m Meter) Quarters() float64 { return float64(m.Beats) * 4 / float64(m.Unit) }
+
+func (m Meter) String() string { return fmt.Sprintf("%d/%d", m.Beats, m.Unit) }
+
+// Musical position, bars and beats start at 1 (ex: {1, 1, 0} is the very beginning).
+type Position struct {
+	Bar, Beat, Tick int
+}
+
+func (p Position) String() string { return fmt.Sprintf("%d:%d:%d", p.Bar, p.Beat, p.Tick) }
+
+// Converts musical positions to time, for a given tempo and meter.
+type Transport struct {
+	BPM          BPM   // Quarter notes per minute
+	Meter        Meter // Defaults to 4/4
+	TicksPerBeat int   // Defaults to 480
+}
+
+// Returns the time at which the position occurs.
+func (t Transport) Time(p Position) time.Duration {
+	m, ticks := t.meter(), t.TicksPerBeat
+	if ticks == 0 {
+		ticks = 480
+	}
+	beats := float64(p.Beat-1) + float64(p.Tick)/float64(ticks)
+	return t.BPM.T(float64(p.Bar-1)*m.Quarters() + beats*4/float64(m.Unit))
+}
+
+// Returns the duration of a single bar.
+func (t Transport) Bar() time.Duration { return t.BPM.T(t.meter().Quarters()) }
+
+// Returns the duration of a single beat (in the unit of the meter).
+func (t Transport) Beat() time.Duration { return t.BPM.T(4 / float64(t.meter().Unit)) }
+
+func (t Transport) meter() Meter {
+	if t.Meter == (Meter{}) {
+		return CommonTime
+	}
+	return t.Meter
+}
@@ -1,8 +1,11 @@
 package music
 
 import (
+	"fmt"
 	"math"
+	"strconv"
 	"time"
+	"unicode"
 )
 
 // Transposes a frequency up or down a given number of semitones (according to the equal tempered scale).
@@ -11,15 +14,70 @@ func Transpose(freq float64, semitones float64) float64 {
 	return float64(freq) * math.Pow(c, semitones)
 }
 
+// Musical note, represented by its MIDI note number (ex: C4 is 60, A4 is 69).
 type Note int
 
-func (n Note) Hz() float64                    { return Transpose(440, float64(n)) }
+func FromMIDI(n int) Note { return Note(n) }
+
+func (n Note) MIDI() int                      { return int(n) }
+func (n Note) Octave() int                    { return int(math.Floor(float64(n)/12)) - 1 }
+func (n Note) Hz() float64                    { return defaultTuning.Hz(n) }
+func (n Note) HzIn(t Tuning) float64          { return t.Hz(n) }
 func (n Note) At(x time.Duration) (y float64) { return n.Hz() }
 
 const (
-	A4 = Note(iota)
-	Bb4
-	B4
+	C0 = Note(12 + iota)
+	Db0
+	D0
+	Eb0
+	E0
+	F0
+	Gb0
+	G0
+	Ab0
+	A0
+	Bb0
+	B0
+
+	C1
+	Db1
+	D1
+	Eb1
+	E1
+	F1
+	Gb1
+	G1
+	Ab1
+	A1
+	Bb1
+	B1
+
+	C2
+	Db2
+	D2
+	Eb2
+	E2
+	F2
+	Gb2
+	G2
+	Ab2
+	A2
+	Bb2
+	B2
+
+	C3
+	Db3
+	D3
+	Eb3
+	E3
+	F3
+	Gb3
+	G3
+	Ab3
+	A3
+	Bb3
+	B3
+
 	C4
 	Db4
 	D4
@@ -29,4 +87,111 @@ const (
 	Gb4
 	G4
 	Ab4
+	A4
+	Bb4
+	B4
+
+	C5
+	Db5
+	D5
+	Eb5
+	E5
+	F5
+	Gb5
+	G5
+	Ab5
+	A5
+	Bb5
+	B5
+
+	C6
+	Db6
+	D6
+	Eb6
+	E6
+	F6
+	Gb6
+	G6
+	Ab6
+	A6
+	Bb6
+	B6
+
+	C7
+	Db7
+	D7
+	Eb7
+	E7
+	F7
+	Gb7
+	G7
+	Ab7
+	A7
+	Bb7
+	B7
+
+	C8
+	Db8
+	D8
+	Eb8
+	E8
+	F8
+	Gb8
+	G8
+	Ab8
+	A8
+	Bb8
+	B8
 )
+
+var (
+	noteNames       = [12]string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+	letterSemitones = map[rune]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+)
+
+// Returns the note name in scientific pitch notation (ex: "C4", "Db5"), using flats for accidentals.
+func (n Note) String() string {
+	return noteNames[((int(n)%12)+12)%12] + strconv.Itoa(n.Octave())
+}
+
+// Parses a note name in scientific pitch notation (ex: "C4", "C#4", "Db5", "f#-1").
+// The letter is case-insensitive, accidentals are "#" (sharp) and "b" (flat).
+func Parse(s string) (Note, error) {
+	if s == "" {
+		return 0, fmt.Errorf("invalid note: empty string")
+	}
+	i, ok := letterSemitones[unicode.ToUpper(rune(s[0]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid note %q: unknown letter %q", s, s[0])
+	}
+	rest := s[1:]
+	for len(rest) > 0 && (rest[0] == '#' || rest[0] == 'b') {
+		if rest[0] == '#' {
+			i++
+		} else {
+			i--
+		}
+		rest = rest[1:]
+	}
+	octave, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid note %q: invalid octave %q", s, rest)
+	}
+	return Note((octave+1)*12 + i), nil
+}
+
+// Returns the note (in the default tuning) closest to the given frequency,
+// and how far the frequency is from it (in cents, from -50 to 50 in 12-EDO).
+//
+// Ex: music.NearestNote(445) is A4, +19.56 cents
+func NearestNote(freq float64) (n Note, cents float64) {
+	guess := Note(math.Round(69 + 12*math.Log2(freq/440)))
+	cents = math.Inf(1)
+	for candidate := guess - 1; candidate <= guess+1; candidate++ {
+		c := 1200 * math.Log2(freq/candidate.Hz())
+		if math.Abs(c) < math.Abs(cents) {
+			n, cents = candidate, c
+		}
+	}
+	return n, cents
+}
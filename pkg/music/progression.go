@@ -0,0 +1,72 @@
+package music
+
+import (
+	"fmt"
+	"strings"
+)
+
+var romanNumerals = []string{"vii", "iv", "vi", "v", "iii", "ii", "i"} // Longest match first
+
+var degrees = map[string]int{"i": 1, "ii": 2, "iii": 3, "iv": 4, "v": 5, "vi": 6, "vii": 7}
+
+// Chord qualities for the suffixes following upper case (major) and lower case (minor) numerals.
+var (
+	majorSuffixes = map[string]ChordQuality{
+		"": MajorTriad, "7": Dominant7, "maj7": Major7, "M7": Major7, "9": Dominant9, "maj9": Major9, "M9": Major9,
+		"+": Augmented, "sus2": Sus2, "sus4": Sus4, "7sus4": Dominant7Sus4, "add9": Add9, "11": Dominant11, "13": Dominant13,
+	}
+	minorSuffixes = map[string]ChordQuality{
+		"": MinorTriad, "7": Minor7, "maj7": MinorMajor7, "M7": MinorMajor7, "9": Minor9, "11": Minor11, "13": Minor13,
+		"o": Diminished, "°": Diminished, "o7": Diminished7, "°7": Diminished7, "ø": HalfDiminished7, "ø7": HalfDiminished7,
+	}
+)
+
+// Expands chords written in Roman numeral notation (separated by spaces) into chords in the given key.
+// Upper case numerals are major chords and lower case numerals are minor chords,
+// they can be prefixed by an accidental ("b" or "#", ex: "bVII") and followed by a quality suffix
+// (ex: "V7", "Imaj7", "ii9", "viio", "vii°7", "viiø7", "III+", "Vsus4").
+//
+// Ex: music.Progression(music.Key{music.C4, music.Major}, "ii V I vi")
+func Progression(key Key, numerals string) ([]Chord, error) {
+	var chords []Chord
+	for _, s := range strings.Fields(numerals) {
+		c, err := parseRomanNumeral(key, s)
+		if err != nil {
+			return nil, err
+		}
+		chords = append(chords, c)
+	}
+	return chords, nil
+}
+
+func parseRomanNumeral(key Key, s string) (Chord, error) {
+	rest, shift := s, Note(0)
+	switch {
+	case strings.HasPrefix(rest, "b"):
+		rest, shift = rest[1:], -1
+	case strings.HasPrefix(rest, "#"):
+		rest, shift = rest[1:], 1
+	}
+
+	for _, numeral := range romanNumerals {
+		if len(rest) < len(numeral) {
+			continue
+		}
+		head, suffix := rest[:len(numeral)], rest[len(numeral):]
+		var qualities map[string]ChordQuality
+		switch head {
+		case numeral:
+			qualities = minorSuffixes
+		case strings.ToUpper(numeral):
+			qualities = majorSuffixes
+		default:
+			continue
+		}
+		quality, ok := qualities[suffix]
+		if !ok {
+			return Chord{}, fmt.Errorf("invalid chord %q: unknown suffix %q", s, suffix)
+		}
+		return Chord{Root: key.Degree(degrees[numeral]) + shift, Quality: quality}, nil
+	}
+	return Chord{}, fmt.Errorf("invalid chord %q: expected a Roman numeral (from I to VII)", s)
+}
@@ -0,0 +1,41 @@
+package music
+
+import (
+	"math"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Returns the note of the key (in any octave) closest to the given frequency.
+func (k Key) Nearest(freq float64) Note {
+	n, _ := NearestNote(freq)
+	best, distance := n, math.Inf(1)
+	for candidate := n - 6; candidate <= n+6; candidate++ {
+		if !k.Scale.Contains(k.Tonic, candidate) {
+			continue
+		}
+		if d := math.Abs(math.Log2(freq / candidate.Hz())); d < distance {
+			best, distance = candidate, d
+		}
+	}
+	return best
+}
+
+// Snaps a frequency signal (in Hertz) to the nearest note of the key,
+// so random or LFO-driven pitches always play in key.
+//
+// Ex: random melody in A minor pentatonic, changing 4 times per second
+//
+//	random := dsp.SampleHold(dsp.Noise(1), dsp.Square(dsp.Constant(4)), rate)
+//	freq := dsp.SignalFunc(func(x time.Duration) float64 { return 440 + 200*random.At(x) })
+//	dsp.Sine(music.Quantize(freq, music.Key{music.A3, music.MinorPentatonic}))
+func Quantize(freq dsp.Signal, key Key) dsp.Signal {
+	return dsp.SignalFunc(func(x time.Duration) (y float64) {
+		f := freq.At(x)
+		if f <= 0 {
+			return 0
+		}
+		return key.Nearest(f).Hz()
+	})
+}
@@ -0,0 +1,29 @@
+package music
+
+// Returns a Euclidean rhythm: the given number of hits spread as evenly as possible over the steps,
+// rotated to the left by the given number of steps (ex: Euclid(3, 8, 0) is "x..x..x.").
+func Euclid(hits, steps, rotation int) []bool {
+	pattern := make([]bool, steps)
+	if steps == 0 {
+		return pattern
+	}
+	hits = max(0, min(hits, steps))
+	for i := range pattern {
+		j := ((i+rotation)%steps + steps) % steps
+		pattern[i] = (j*hits)%steps < hits
+	}
+	return pattern
+}
+
+// Returns the steps playing the given notes where the pattern is true, and rests elsewhere.
+//
+// Ex: music.StepSequencer{Steps: music.PatternSteps(music.Euclid(3, 8, 0), music.C2), ...}
+func PatternSteps(pattern []bool, notes ...Note) []Step {
+	steps := make([]Step, len(pattern))
+	for i, hit := range pattern {
+		if hit {
+			steps[i].Notes = notes
+		}
+	}
+	return steps
+}
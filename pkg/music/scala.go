@@ -0,0 +1,190 @@
+package music
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale loaded from a Scala scale file (.scl), see https://www.huygens-fokker.org/scala/scl_format.html.
+type ScalaScale struct {
+	Description string
+	Pitches     []float64 // Ratio of each degree from the first one (which is implicit), the last one is the period
+}
+
+// Keyboard mapping loaded from a Scala keyboard mapping file (.kbm),
+// it defines which scale degree each note plays and how the scale is tuned.
+type KeyboardMapping struct {
+	First, Last  Note    // Range of notes to retune
+	Middle       Note    // Note playing the first degree of the scale
+	Reference    Note    // Note tuned to the reference frequency
+	Frequency    float64 // Reference frequency (in Hertz)
+	OctaveDegree int     // Scale degree reached after a full mapping (when Mapping is not empty)
+	Mapping      []int   // Scale degree played by each note from Middle (-1 for unmapped notes), empty for a linear mapping
+}
+
+// Mapping used when no keyboard mapping file is provided: linear mapping from C4, with A4 tuned to 440 Hz.
+var DefaultKeyboardMapping = KeyboardMapping{First: 0, Last: 127, Middle: C4, Reference: A4, Frequency: 440}
+
+func ParseScala(r io.Reader) (ScalaScale, error) {
+	var s ScalaScale
+	lines, err := scalaLines(r, true)
+	if err != nil {
+		return s, fmt.Errorf("scala scale: %w", err)
+	}
+	if len(lines) < 2 {
+		return s, fmt.Errorf("scala scale: missing description or number of notes")
+	}
+	s.Description = lines[0].text
+	n, err := strconv.Atoi(firstField(lines[1].text))
+	if err != nil {
+		return s, fmt.Errorf("scala scale: line %d: invalid number of notes: %w", lines[1].num, err)
+	}
+	if len(lines)-2 != n {
+		return s, fmt.Errorf("scala scale: expected %d notes but got %d", n, len(lines)-2)
+	}
+	for _, l := range lines[2:] {
+		p, err := parseScalaPitch(firstField(l.text))
+		if err != nil {
+			return s, fmt.Errorf("scala scale: line %d: %w", l.num, err)
+		}
+		s.Pitches = append(s.Pitches, p)
+	}
+	return s, nil
+}
+
+func ParseKeyboardMapping(r io.Reader) (KeyboardMapping, error) {
+	var m KeyboardMapping
+	lines, err := scalaLines(r, false)
+	if err != nil {
+		return m, fmt.Errorf("keyboard mapping: %w", err)
+	}
+	if len(lines) < 7 {
+		return m, fmt.Errorf("keyboard mapping: expected at least 7 lines but got %d", len(lines))
+	}
+	var values [7]float64
+	for i, l := range lines[:7] {
+		v, err := strconv.ParseFloat(firstField(l.text), 64)
+		if err != nil {
+			return m, fmt.Errorf("keyboard mapping: line %d: %w", l.num, err)
+		}
+		values[i] = v
+	}
+	size := int(values[0])
+	m.First, m.Last, m.Middle, m.Reference = Note(values[1]), Note(values[2]), Note(values[3]), Note(values[4])
+	m.Frequency, m.OctaveDegree = values[5], int(values[6])
+	for _, l := range lines[7:] {
+		if len(m.Mapping) == size {
+			break
+		}
+		if f := firstField(l.text); f == "x" {
+			m.Mapping = append(m.Mapping, -1)
+		} else if d, err := strconv.Atoi(f); err != nil {
+			return m, fmt.Errorf("keyboard mapping: line %d: %w", l.num, err)
+		} else {
+			m.Mapping = append(m.Mapping, d)
+		}
+	}
+	// Missing entries at the end of the mapping are unmapped.
+	for len(m.Mapping) < size {
+		m.Mapping = append(m.Mapping, -1)
+	}
+	return m, nil
+}
+
+// Returns the tuning of the scale with the given keyboard mapping.
+// Unmapped notes (and notes out of the mapping range) have a frequency of 0.
+func (s ScalaScale) Tuning(m KeyboardMapping) Tuning { return scalaTuning{s, m} }
+
+type scalaTuning struct {
+	scale   ScalaScale
+	mapping KeyboardMapping
+}
+
+func (t scalaTuning) Hz(n Note) float64 {
+	if n < t.mapping.First || n > t.mapping.Last {
+		return 0
+	}
+	degree, ok := t.degree(n)
+	ref, refOK := t.degree(t.mapping.Reference)
+	if !ok || !refOK {
+		return 0
+	}
+	return t.mapping.Frequency * t.ratio(degree) / t.ratio(ref)
+}
+
+// Returns the scale degree played by the note.
+func (t scalaTuning) degree(n Note) (int, bool) {
+	d := int(n - t.mapping.Middle)
+	size := len(t.mapping.Mapping)
+	if size == 0 {
+		return d, true
+	}
+	octave := int(math.Floor(float64(d) / float64(size)))
+	degree := t.mapping.Mapping[d-octave*size]
+	return degree + octave*t.mapping.OctaveDegree, degree >= 0
+}
+
+// Returns the ratio of the given degree from the first degree of the scale.
+func (t scalaTuning) ratio(degree int) float64 {
+	size := len(t.scale.Pitches)
+	if size == 0 {
+		return 1
+	}
+	octave := int(math.Floor(float64(degree) / float64(size)))
+	ratio := math.Pow(t.scale.Pitches[size-1], float64(octave))
+	if i := degree - octave*size; i > 0 {
+		ratio *= t.scale.Pitches[i-1]
+	}
+	return ratio
+}
+
+// Parses a pitch written in cents (if it contains a period) or as a ratio (ex: "3/2" or "2").
+func parseScalaPitch(s string) (float64, error) {
+	if strings.Contains(s, ".") {
+		cents, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid pitch %q: %w", s, err)
+		}
+		return math.Pow(2, cents/1200), nil
+	}
+	num, den, found := strings.Cut(s, "/")
+	a, err := strconv.ParseUint(num, 10, 64)
+	b := uint64(1)
+	if err == nil && found {
+		b, err = strconv.ParseUint(den, 10, 64)
+	}
+	if err != nil || a == 0 || b == 0 {
+		return 0, fmt.Errorf("invalid pitch %q", s)
+	}
+	return float64(a) / float64(b), nil
+}
+
+type scalaLine struct {
+	num  int
+	text string
+}
+
+// Returns the lines that are not comments (starting with "!"),
+// blank lines are also skipped unless keepFirst is true (for the description of .scl files).
+func scalaLines(r io.Reader, keepFirst bool) (lines []scalaLine, err error) {
+	sc := bufio.NewScanner(r)
+	for num := 1; sc.Scan(); num++ {
+		text := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(text, "!") || (text == "" && !(keepFirst && len(lines) == 0)) {
+			continue
+		}
+		lines = append(lines, scalaLine{num, text})
+	}
+	return lines, sc.Err()
+}
+
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
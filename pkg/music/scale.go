@@ -0,0 +1,63 @@
+package music
+
+// Scale, defined by the intervals (in semitones) of its degrees from the root, within one octave.
+type Scale []int
+
+var (
+	Major           = Scale{0, 2, 4, 5, 7, 9, 11}
+	NaturalMinor    = Scale{0, 2, 3, 5, 7, 8, 10}
+	HarmonicMinor   = Scale{0, 2, 3, 5, 7, 8, 11}
+	MelodicMinor    = Scale{0, 2, 3, 5, 7, 9, 11} // Ascending form
+	MajorPentatonic = Scale{0, 2, 4, 7, 9}
+	MinorPentatonic = Scale{0, 3, 5, 7, 10}
+	Blues           = Scale{0, 3, 5, 6, 7, 10}
+
+	// Church modes
+	Ionian     = Major
+	Dorian     = Major.Mode(2)
+	Phrygian   = Major.Mode(3)
+	Lydian     = Major.Mode(4)
+	Mixolydian = Major.Mode(5)
+	Aeolian    = Major.Mode(6)
+	Locrian    = Major.Mode(7)
+)
+
+// Returns the scale starting on its n-th degree (ex: Major.Mode(2) is Dorian).
+func (s Scale) Mode(n int) Scale {
+	root := s.Degree(n)
+	mode := make(Scale, len(s))
+	for i := range s {
+		mode[i] = s.Degree(n+i) - root
+	}
+	return mode
+}
+
+// Returns the interval (in semitones) between the root and the n-th degree (starting at 1).
+// Degrees beyond the octave (or below 1) continue in the next (or previous) octaves.
+func (s Scale) Degree(n int) int {
+	i, octave := n-1, 0
+	for i < 0 {
+		i, octave = i+len(s), octave-1
+	}
+	return s[i%len(s)] + 12*(octave+i/len(s))
+}
+
+// Reports whether the note belongs to the scale starting on the given root (in any octave).
+func (s Scale) Contains(root, n Note) bool {
+	interval := ((int(n-root) % 12) + 12) % 12
+	for _, v := range s {
+		if v == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the notes of the scale starting on the given root (over one octave).
+func (s Scale) Notes(root Note) []Note {
+	notes := make([]Note, len(s))
+	for i, v := range s {
+		notes[i] = root + Note(v)
+	}
+	return notes
+}
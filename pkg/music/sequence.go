@@ -0,0 +1,82 @@
+package music
+
+import (
+	"math"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Notes and rests played one after the other, timed in notation terms: the tempo is only needed to play them.
+// Unlike a piano roll, silences are part of the sequence, so they count in its length and stay on the grid when quantizing.
+//
+//	music.Sequence{music.Play(music.Eighth, music.C4), music.Rest(music.Eighth), music.Play(music.Quarter, music.E4, music.G4)}
+type Sequence []Element
+
+// Notes played together (one note, a chord) or a rest (no notes), lasting a rhythmic duration.
+type Element struct {
+	Notes    []Note
+	Dur      Dur
+	Velocity float64 // From 0 to 1, defaults to 0.75
+}
+
+// Returns an element playing the notes together.
+func Play(d Dur, notes ...Note) Element { return Element{Notes: notes, Dur: d} }
+
+// Returns a silent element.
+func Rest(d Dur) Element { return Element{Dur: d} }
+
+func (e Element) IsRest() bool { return len(e.Notes) == 0 }
+
+func (e Element) velocity() float64 {
+	if e.Velocity == 0 {
+		return 0.75
+	}
+	return e.Velocity
+}
+
+// Returns the total duration of the elements (including the rests at the end).
+func (s Sequence) Length() (d Dur) {
+	for _, e := range s {
+		d += e.Dur
+	}
+	return d
+}
+
+// Returns the notes played at the given tempo.
+func (s Sequence) PianoRoll(bpm BPM) (notes PianoRoll) {
+	var at Dur
+	for _, e := range s {
+		start, end := at.T(bpm), (at + e.Dur).T(bpm)
+		for _, n := range e.Notes {
+			notes = append(notes, NoteEvent{Note: n, Start: start, Duration: end - start, Velocity: e.velocity()})
+		}
+		at += e.Dur
+	}
+	return notes
+}
+
+// Returns the sequence played by a synth using the given instrument, lasting until the end of the last element.
+func (s Sequence) Signal(bpm BPM, instrument Instrument) dsp.FiniteSignal {
+	return playNotes(s.PianoRoll(bpm), s.Length().T(bpm), instrument)
+}
+
+// Moves the start and end of each element (notes and rests) to the closest multiple of the grid (ex: Sixteenth),
+// elements shorter than the grid are dropped when their start and end fall on the same position.
+func (s Sequence) Quantize(grid Dur) Sequence {
+	if grid <= 0 {
+		return append(Sequence(nil), s...)
+	}
+	snap := func(d Dur) Dur { return Dur(math.Round(float64(d/grid))) * grid }
+	var quantized Sequence
+	var at Dur
+	for _, e := range s {
+		start, end := snap(at), snap(at+e.Dur)
+		at += e.Dur
+		if end <= start {
+			continue
+		}
+		e.Dur = end - start
+		quantized = append(quantized, e)
+	}
+	return quantized
+}
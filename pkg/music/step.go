@@ -0,0 +1,110 @@
+package music
+
+import (
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Step of a step sequencer.
+type Step struct {
+	Notes      []Note  // Notes played at this step, none for a rest
+	Accent     bool    // Accented steps are played at full velocity (others at 3/4)
+	Gate       float64 // Proportion of the step (or of each ratchet) during which the notes are played, defaults to 1
+	SkipChance float64 // Chance (from 0 to 1) that the step is skipped each time the bar loops, 0 means it's always played
+	Ratchet    int     // Number of times the notes are retriggered within the step, defaults to 1
+}
+
+// Plays one step after the other, looping over a single bar.
+type StepSequencer struct {
+	BPM         BPM
+	Meter       Meter // Defaults to 4/4
+	StepsPerBar int   // Defaults to the number of steps
+	Steps       []Step
+	Swing       float64 // Proportion of each pair of steps given to the first one, from 0.5 (straight) to 0.75
+	Instrument  Instrument
+	Humanize    Humanize // Random variations of the start and level of each step (different each time the bar loops)
+	Seed        uint64   // Random seed deciding which steps are played (see Step.SkipChance) and how they're humanized
+}
+
+// Returns the looping signal of the sequencer, lasting one bar.
+func (s StepSequencer) Signal() dsp.FiniteSignal {
+	stepsPerBar := s.StepsPerBar
+	if stepsPerBar == 0 {
+		stepsPerBar = len(s.Steps)
+	}
+	swing := s.Swing
+	if swing == 0 {
+		swing = 0.5
+	}
+	step := Transport{BPM: s.BPM, Meter: s.Meter}.Bar() / time.Duration(stepsPerBar)
+
+	// Compute when each step (and each of its ratchets) starts, how long its notes last, and what it plays.
+	starts, ratchets, gates := make([]time.Duration, len(s.Steps)), make([]time.Duration, len(s.Steps)), make([]time.Duration, len(s.Steps))
+	voices, velocities := make([]dsp.Signal, len(s.Steps)), make([]float64, len(s.Steps))
+	var total time.Duration
+	for i, st := range s.Steps {
+		d := time.Duration(2 * swing * float64(step))
+		if i%2 == 1 {
+			d = 2*step - d
+		} else if i == len(s.Steps)-1 {
+			d = step // The last step of an odd number of steps isn't swung, so the loop still lasts a bar
+		}
+		gate := st.Gate
+		if gate == 0 {
+			gate = 1
+		}
+		ratchet := d / time.Duration(max(st.Ratchet, 1))
+		starts[i], ratchets[i], gates[i] = total, ratchet, time.Duration(gate*float64(ratchet))
+		total += d
+		if len(st.Notes) == 0 {
+			continue
+		}
+		velocity := 0.75
+		if st.Accent {
+			velocity = 1
+		}
+		velocities[i] = velocity
+		notes := make([]dsp.Signal, len(st.Notes))
+		for j, n := range st.Notes {
+			notes[j] = s.Instrument(n, velocity)
+		}
+		voices[i] = dsp.Combine(notes...)
+	}
+	if total == 0 {
+		return dsp.Blank(0)
+	}
+
+	// Random values (between -1 and 1) for each step of each loop.
+	chance, timing, level := dsp.Noise(s.Seed), dsp.Noise(s.Seed+1), dsp.Noise(s.Seed+2)
+	return dsp.F(total, dsp.SignalFunc(func(x time.Duration) (y float64) {
+		loop := x / total
+		x %= total
+		id := func(i int) time.Duration { return loop*time.Duration(len(starts)) + time.Duration(i) }
+		for i := len(starts) - 1; i >= 0; i-- {
+			start := starts[i]
+			if s.Humanize.Timing != 0 {
+				start = max(0, start+time.Duration(timing.At(id(i))*float64(s.Humanize.Timing)))
+			}
+			if x < start {
+				continue
+			}
+			if voices[i] == nil {
+				return 0
+			}
+			if (chance.At(id(i))+1)/2 < s.Steps[i].SkipChance {
+				return 0
+			}
+			local := (x - start) % ratchets[i]
+			if local >= gates[i] {
+				return 0
+			}
+			gain := 1.0
+			if s.Humanize.Velocity != 0 {
+				gain = max(0, 1+level.At(id(i))*s.Humanize.Velocity/velocities[i])
+			}
+			return gain * voices[i].At(local)
+		}
+		return 0
+	}))
+}
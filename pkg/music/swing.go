@@ -0,0 +1,55 @@
+package music
+
+import (
+	"math"
+	"time"
+)
+
+// Swings the notes (like StepSequencer.Swing): within each pair of grid steps (ex: bpm.T(0.25) for 16th notes),
+// the first step lasts the given proportion of the pair, from 0.5 (straight, 0 also means straight) to 0.75 (hard swing),
+// so notes on the off-beat step are played later. Starts and ends of notes are both moved.
+//
+// Ex: notes.Swing(bpm.T(0.5), 0.66) for a triplet feel on eighth notes
+func (r PianoRoll) Swing(grid time.Duration, amount float64) PianoRoll {
+	swung := make(PianoRoll, len(r))
+	for i, e := range r {
+		start := time.Duration(swing(float64(e.Start), float64(grid), amount))
+		end := time.Duration(swing(float64(e.End()), float64(grid), amount))
+		e.Start, e.Duration = start, end-start
+		swung[i] = e
+	}
+	return swung
+}
+
+// Swings the sequence (see PianoRoll.Swing) on a grid of rhythmic durations (ex: Sixteenth),
+// rests are moved like notes, so the length of the sequence is unchanged (when it's a whole number of pairs of steps).
+func (s Sequence) Swing(grid Dur, amount float64) Sequence {
+	swung := make(Sequence, len(s))
+	var at Dur
+	for i, e := range s {
+		start, end := swing(float64(at), float64(grid), amount), swing(float64(at+e.Dur), float64(grid), amount)
+		at += e.Dur
+		e.Dur = Dur(end - start)
+		swung[i] = e
+	}
+	return swung
+}
+
+// Moves a position so that the first half of each pair of grid steps takes the given proportion of the pair.
+func swing(x, grid, amount float64) float64 {
+	if amount == 0 {
+		amount = 0.5
+	}
+	pair := 2 * grid
+	if pair <= 0 {
+		return x
+	}
+	start := math.Floor(x/pair) * pair
+	u := (x - start) / pair
+	if u < 0.5 {
+		u *= 2 * amount
+	} else {
+		u = amount + (u-0.5)*2*(1-amount)
+	}
+	return start + u*pair
+}
@@ -0,0 +1,189 @@
+package music
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Polyphonic synthesizer playing notes scheduled at given positions.
+// Each note is played by a voice (a signal from the instrument, shaped by the envelope),
+// and the synth signal is the sum of all voices.
+//
+// Notes should be scheduled in chronological order (voice allocation only knows about previous notes),
+// and can be scheduled while the synth is being played (it is safe for concurrent use).
+//
+//	synth := &music.Synth{Instrument: music.Oscillator(dsp.Saw), MaxVoices: 8}
+//	synth.Play(music.NoteEvent{Note: music.A4, Start: 0, Duration: bpm.T(1), Velocity: 0.8})
+type Synth struct {
+	Instrument Instrument
+	Envelope   dsp.ADSR    // Defaults to DefaultEnvelope
+	MaxVoices  int         // Maximum number of voices playing at the same time, 0 means unlimited
+	Steal      StealPolicy // Which voice is stopped to play a new note when all voices are busy
+
+	mu     sync.Mutex
+	voices []*voice // Sorted by start
+
+	// Voices playing at the last position played, and index of the first voice starting after it,
+	// so that playing doesn't go through voices that are over (positions are usually played in order).
+	active []*voice
+	next   int
+	last   time.Duration
+}
+
+// Envelope used by synths without one: a short fade in and out.
+var DefaultEnvelope = dsp.ADSR{Attack: 5 * time.Millisecond, Sustain: 1, Release: 50 * time.Millisecond}
+
+// Time it takes to fade out a stolen voice.
+const StealRelease = 5 * time.Millisecond
+
+type StealPolicy int
+
+const (
+	StealOldest  StealPolicy = iota // Stops the voice that started first
+	StealLowest                     // Stops the voice playing the lowest note
+	StealHighest                    // Stops the voice playing the highest note
+	StealNone                       // Doesn't play new notes
+)
+
+type voice struct {
+	note     Note
+	signal   dsp.Signal
+	envelope dsp.ADSR
+	start    time.Duration
+	released time.Duration // Relative to start, negative while the note is held
+}
+
+func (v *voice) end() time.Duration {
+	if v.released < 0 {
+		return 1<<63 - 1
+	}
+	return v.start + v.released + v.envelope.Release
+}
+
+// Starts playing a note at the given position.
+func (s *Synth) NoteOn(at time.Duration, n Note, velocity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noteOn(at, n, velocity, nil)
+}
+
+// Returns the new voice (or nil if the note isn't played).
+func (s *Synth) noteOn(at time.Duration, n Note, velocity float64, bend dsp.Signal) *voice {
+	env := s.Envelope
+	if env == (dsp.ADSR{}) {
+		env = DefaultEnvelope
+	}
+
+	if s.MaxVoices > 0 {
+		var busy []*voice
+		for _, v := range s.voices {
+			if v.start <= at && at < v.end() {
+				busy = append(busy, v)
+			}
+		}
+		if len(busy) >= s.MaxVoices {
+			if s.Steal == StealNone {
+				return nil
+			}
+			v := s.victim(busy)
+			v.envelope.Release = min(v.envelope.Release, StealRelease)
+			if v.released < 0 || v.start+v.released > at {
+				v.released = at - v.start
+			}
+		}
+	}
+
+	signal := s.Instrument(n, velocity)
+	if bend != nil {
+		signal = dsp.Bend(signal, bend)
+	}
+	v := &voice{note: n, signal: signal, envelope: env, start: at, released: -1}
+	i, _ := slices.BinarySearchFunc(s.voices, at, func(v *voice, at time.Duration) int {
+		if v.start <= at {
+			return -1
+		}
+		return 1
+	})
+	s.voices = slices.Insert(s.voices, i, v)
+	if i < s.next {
+		s.next++
+		s.active = append(s.active, v)
+	}
+	return v
+}
+
+// Returns the voice to stop, voices that are already released are stopped first.
+func (s *Synth) victim(busy []*voice) *voice {
+	var better func(a, b *voice) bool
+	switch s.Steal {
+	case StealOldest:
+		better = func(a, b *voice) bool { return a.start < b.start }
+	case StealLowest:
+		better = func(a, b *voice) bool { return a.note < b.note }
+	case StealHighest:
+		better = func(a, b *voice) bool { return a.note > b.note }
+	default:
+		panic(fmt.Errorf("unknown steal policy: %d", s.Steal))
+	}
+	victim := busy[0]
+	for _, v := range busy[1:] {
+		if vr, br := v.released >= 0, victim.released >= 0; vr != br {
+			if vr {
+				victim = v
+			}
+		} else if better(v, victim) {
+			victim = v
+		}
+	}
+	return victim
+}
+
+// Releases the (oldest) held voice playing the note at the given position.
+func (s *Synth) NoteOff(at time.Duration, n Note) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.voices {
+		if v.note == n && v.released < 0 && v.start <= at {
+			v.released = at - v.start
+			return
+		}
+	}
+}
+
+// Schedules a note (NoteOn and NoteOff), with its pitch bend.
+func (s *Synth) Play(e NoteEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v := s.noteOn(e.Start, e.Note, e.Velocity, e.Bend); v != nil {
+		v.released = e.Duration
+	}
+}
+
+func (s *Synth) At(x time.Duration) (y float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if x < s.last {
+		s.active, s.next = s.active[:0], 0
+	}
+	s.last = x
+	for ; s.next < len(s.voices) && s.voices[s.next].start <= x; s.next++ {
+		s.active = append(s.active, s.voices[s.next])
+	}
+	s.active = slices.DeleteFunc(s.active, func(v *voice) bool { return x >= v.end() })
+	for _, v := range s.active {
+		y += v.envelope.Gain(x-v.start, v.released) * v.signal.At(x-v.start)
+	}
+	return y
+}
+
+// Forgets the voices that are over before the given position, to free memory when playing live.
+func (s *Synth) Prune(before time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voices = slices.DeleteFunc(s.voices, func(v *voice) bool { return v.end() <= before })
+	s.active, s.next = s.active[:0], 0
+}
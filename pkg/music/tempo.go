@@ -0,0 +1,84 @@
+package music
+
+import (
+	"math"
+	"time"
+)
+
+// Tempo changing over time (ex: ritardando, accelerando).
+// It's made of points, sorted by beat, between which the tempo changes linearly,
+// the tempo stays constant before the first point and after the last one.
+//
+// Ex: slowing down from 120 to 90 BPM between the 16th and the 24th beat:
+//
+//	music.TempoMap{{Beat: 16, BPM: 120}, {Beat: 24, BPM: 90}}
+type TempoMap []TempoPoint
+
+type TempoPoint struct {
+	Beat float64
+	BPM  BPM
+}
+
+// Returns the time at which the given beat occurs (like BPM.T), by integrating the tempo curve.
+func (m TempoMap) T(beats float64) time.Duration {
+	var minutes float64
+	m.segments(func(from, to float64, bpm, slope float64) bool {
+		d := math.Min(beats, to) - from
+		if d <= 0 {
+			return false
+		}
+		if slope == 0 {
+			minutes += d / bpm
+		} else {
+			minutes += math.Log((bpm+slope*d)/bpm) / slope
+		}
+		return beats > to
+	})
+	return time.Duration(minutes * float64(time.Minute))
+}
+
+// Returns the number of beats elapsed at the given time (the inverse of T).
+func (m TempoMap) Beats(t time.Duration) float64 {
+	var beats float64
+	left := t.Minutes()
+	m.segments(func(from, to float64, bpm, slope float64) bool {
+		// Duration of the whole segment (in minutes).
+		length := (to - from) / bpm
+		if slope != 0 {
+			length = math.Log((bpm+slope*(to-from))/bpm) / slope
+		}
+		if left >= length {
+			beats, left = to, left-length
+			return true
+		}
+		if slope == 0 {
+			beats = from + left*bpm
+		} else {
+			beats = from + bpm*(math.Exp(slope*left)-1)/slope
+		}
+		return false
+	})
+	return beats
+}
+
+// Calls fn for each segment of the tempo curve (starting at beat 0), until it returns false.
+// The tempo goes from bpm (at the start of the segment) with the given slope (BPM per beat).
+// The last segment never ends.
+func (m TempoMap) segments(fn func(from, to float64, bpm, slope float64) bool) {
+	if len(m) == 0 {
+		return
+	}
+	from, bpm := 0.0, float64(m[0].BPM)
+	for _, p := range m {
+		if p.Beat <= from {
+			from, bpm = math.Max(from, p.Beat), float64(p.BPM)
+			continue
+		}
+		slope := (float64(p.BPM) - bpm) / (p.Beat - from)
+		if !fn(from, p.Beat, bpm, slope) {
+			return
+		}
+		from, bpm = p.Beat, float64(p.BPM)
+	}
+	fn(from, math.Inf(1), bpm, 0)
+}
@@ -0,0 +1,88 @@
+package music
+
+import (
+	"math"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Maps notes to frequencies (in Hertz).
+type Tuning interface {
+	Hz(n Note) float64
+}
+
+// Returns the tuning used by Note.Hz (and thus when notes are used as signals and by instruments):
+// 12-EDO with A4 at 440 Hz. Other temperaments or reference pitches are used with Note.HzIn and Tuned.
+func DefaultTuning() Tuning { return defaultTuning }
+
+var defaultTuning = EDO{Divisions: 12, Reference: A4, Pitch: 440}
+
+// Plays the instrument in the given tuning (ex: EDO{12, A4, 432}, JustIntonation(C4, 261.63)),
+// each note being played faster or slower (see dsp.Speed) to move it from its default frequency to its frequency in t.
+// The whole voice is sped up or slowed down, including its envelope and decay:
+// it's meant for small deviations from the default tuning (a few semitones at most), not for ex: 24-EDO far from its reference,
+// where notes are several times faster or slower (instruments must compute their frequency with Note.HzIn instead).
+func Tuned(instrument Instrument, t Tuning) Instrument {
+	return func(n Note, velocity float64) dsp.Signal {
+		voice := instrument(n, velocity)
+		ratio := n.HzIn(t) / n.Hz()
+		if ratio == 1 {
+			return voice
+		}
+		return dsp.Speed(voice, dsp.Constant(ratio))
+	}
+}
+
+// Equal division of the octave in a given number of steps, each note being one step
+// (ex: 12-EDO is the standard equal temperament, 24-EDO adds quarter tones).
+type EDO struct {
+	Divisions int
+	Reference Note    // Note tuned to the reference pitch (ex: A4)
+	Pitch     float64 // Reference pitch (in Hertz)
+}
+
+func (t EDO) Hz(n Note) float64 {
+	return t.Pitch * math.Pow(2, float64(n-t.Reference)/float64(t.Divisions))
+}
+
+// Tuning defined by the frequency ratios of each step from the tonic,
+// repeating every period (ex: just intonation, Pythagorean tuning, Scala scales).
+// Without ratios, notes have their default frequency (see DefaultTuning).
+type RatioTuning struct {
+	Ratios []float64 // Ratio of each step from the tonic, starting with 1
+	Period float64   // Ratio after which the steps repeat, defaults to 2 (the octave)
+	Tonic  Note
+	Pitch  float64 // Frequency of the tonic (in Hertz)
+}
+
+func (t RatioTuning) Hz(n Note) float64 {
+	if len(t.Ratios) == 0 {
+		return defaultTuning.Hz(n)
+	}
+	period := t.Period
+	if period == 0 {
+		period = 2
+	}
+	steps := int(n - t.Tonic)
+	octave := int(math.Floor(float64(steps) / float64(len(t.Ratios))))
+	i := steps - octave*len(t.Ratios)
+	return t.Pitch * t.Ratios[i] * math.Pow(period, float64(octave))
+}
+
+// 5-limit just intonation, starting on the given tonic (tuned to the given pitch).
+func JustIntonation(tonic Note, pitch float64) RatioTuning {
+	return RatioTuning{
+		Ratios: []float64{1, 16.0 / 15, 9.0 / 8, 6.0 / 5, 5.0 / 4, 4.0 / 3, 45.0 / 32, 3.0 / 2, 8.0 / 5, 5.0 / 3, 9.0 / 5, 15.0 / 8},
+		Tonic:  tonic,
+		Pitch:  pitch,
+	}
+}
+
+// Pythagorean tuning (based on pure fifths), starting on the given tonic (tuned to the given pitch).
+func Pythagorean(tonic Note, pitch float64) RatioTuning {
+	return RatioTuning{
+		Ratios: []float64{1, 256.0 / 243, 9.0 / 8, 32.0 / 27, 81.0 / 64, 4.0 / 3, 729.0 / 512, 3.0 / 2, 128.0 / 81, 27.0 / 16, 16.0 / 9, 243.0 / 128},
+		Tonic:  tonic,
+		Pitch:  pitch,
+	}
+}
@@ -0,0 +1,49 @@
+package music
+
+import (
+	"math"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Maps note velocities (from 0 to 1) to other velocities, to change how an instrument responds to playing dynamics
+// (instruments play notes with an amplitude proportional to the velocity).
+type VelocityCurve func(velocity float64) float64
+
+var (
+	LinearVelocity VelocityCurve = func(v float64) float64 { return v }
+
+	// Spreads velocities over 40 dB (velocity 0.5 is 20 dB quieter than velocity 1), closer to how loudness is perceived.
+	DecibelVelocity VelocityCurve = func(v float64) float64 {
+		if v <= 0 {
+			return 0
+		}
+		return math.Pow(10, 2*(math.Min(v, 1)-1))
+	}
+
+	// Quiet notes are louder than with a linear curve (velocity 0.5 has a gain of 0.71).
+	SoftVelocity VelocityCurve = math.Sqrt
+)
+
+// Returns the instrument playing notes with their velocity mapped by the curve.
+func (c VelocityCurve) Apply(instrument Instrument) Instrument {
+	return func(n Note, velocity float64) dsp.Signal { return instrument(n, c(velocity)) }
+}
+
+// Velocity-sensitive low-pass filter: notes played harder are brighter.
+// The cutoff (in Hertz) starts at base + velocity·amount and falls back to base over the decay
+// (like a filter envelope whose intensity follows the velocity), a decay of 0 keeps the cutoff constant.
+//
+// Ex: music.VelocityFilter(music.Oscillator(dsp.Saw), rate, 400, 4000, 300*time.Millisecond)
+func VelocityFilter(instrument Instrument, rate int, base, amount float64, decay time.Duration) Instrument {
+	return func(n Note, velocity float64) dsp.Signal {
+		cutoff := dsp.SignalFunc(func(x time.Duration) (y float64) {
+			if decay <= 0 {
+				return base + velocity*amount
+			}
+			return base + velocity*amount*math.Exp(-5*x.Seconds()/decay.Seconds())
+		})
+		return dsp.LowPass(instrument(n, velocity), rate, cutoff, dsp.Constant(0.707))
+	}
+}
@@ -0,0 +1,196 @@
+// Package osc sends and receives Open Sound Control messages over UDP,
+// so that external tools (TouchOSC, SuperCollider, Max, etc.) can control running patches.
+//
+// Incoming messages are usually bound to parameters, which are signals that can be used anywhere in a patch:
+//
+//	cutoff := osc.NewParam(1000)
+//	srv := osc.NewServer()
+//	srv.Bind("/filter/cutoff", cutoff)
+//	go srv.ListenAndServe(ctx, ":9000")
+//	dsp.LowPass(in, 44100, cutoff, dsp.Constant(0.7))
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// OSC message: an address (ex: "/filter/cutoff") and its arguments.
+// Supported argument types are int32, int64, float32, float64, string, []byte and bool.
+type Message struct {
+	Address string
+	Args    []any
+}
+
+// Returns the argument at index i as a float64 (if it's a number or a bool).
+func (m Message) Float(i int) (v float64, ok bool) {
+	if i >= len(m.Args) {
+		return 0, false
+	}
+	switch a := m.Args[i].(type) {
+	case int32:
+		return float64(a), true
+	case int64:
+		return float64(a), true
+	case float32:
+		return float64(a), true
+	case float64:
+		return a, true
+	case bool:
+		if a {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Encodes the message in the OSC binary format.
+func (m Message) MarshalBinary() ([]byte, error) {
+	tags := []byte{','}
+	var args []byte
+	for _, arg := range m.Args {
+		switch a := arg.(type) {
+		case int32:
+			tags = append(tags, 'i')
+			args = binary.BigEndian.AppendUint32(args, uint32(a))
+		case int64:
+			tags = append(tags, 'h')
+			args = binary.BigEndian.AppendUint64(args, uint64(a))
+		case float32:
+			tags = append(tags, 'f')
+			args = binary.BigEndian.AppendUint32(args, math.Float32bits(a))
+		case float64:
+			tags = append(tags, 'd')
+			args = binary.BigEndian.AppendUint64(args, math.Float64bits(a))
+		case string:
+			tags = append(tags, 's')
+			args = appendString(args, a)
+		case []byte:
+			tags = append(tags, 'b')
+			args = binary.BigEndian.AppendUint32(args, uint32(len(a)))
+			args = append(args, a...)
+			args = pad(args)
+		case bool:
+			if a {
+				tags = append(tags, 'T')
+			} else {
+				tags = append(tags, 'F')
+			}
+		default:
+			return nil, fmt.Errorf("unsupported OSC argument type: %T", arg)
+		}
+	}
+	b := appendString(nil, m.Address)
+	b = appendString(b, string(tags))
+	return append(b, args...), nil
+}
+
+// Appends a null-terminated string padded to a multiple of 4 bytes.
+func appendString(b []byte, s string) []byte {
+	return pad(append(append(b, s...), 0))
+}
+
+func pad(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// Decodes a packet (a message or a bundle of messages) in the OSC binary format.
+// Bundles are flattened (their time tags are ignored, messages are meant to be applied right away).
+func Decode(b []byte) (messages []Message, err error) {
+	if bytes.HasPrefix(b, []byte("#bundle\x00")) {
+		if len(b) < 16 {
+			return nil, errors.New("invalid OSC bundle: too short")
+		}
+		for b = b[16:]; len(b) > 0; {
+			if len(b) < 4 {
+				return nil, errors.New("invalid OSC bundle: truncated element size")
+			}
+			size := int(binary.BigEndian.Uint32(b))
+			if size < 0 || 4+size > len(b) {
+				return nil, errors.New("invalid OSC bundle: truncated element")
+			}
+			inner, err := Decode(b[4 : 4+size])
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, inner...)
+			b = b[4+size:]
+		}
+		return messages, nil
+	}
+	m, err := decodeMessage(b)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{m}, nil
+}
+
+func decodeMessage(b []byte) (m Message, err error) {
+	if m.Address, b, err = readString(b); err != nil {
+		return m, fmt.Errorf("invalid OSC address: %w", err)
+	}
+	if len(m.Address) == 0 || m.Address[0] != '/' {
+		return m, fmt.Errorf("invalid OSC address %q", m.Address)
+	}
+	if len(b) == 0 {
+		return m, nil // Some old implementations omit the type tags when there are no arguments.
+	}
+	tags, b, err := readString(b)
+	if err != nil || len(tags) == 0 || tags[0] != ',' {
+		return m, errors.New("invalid OSC type tags")
+	}
+	for _, tag := range tags[1:] {
+		size := map[rune]int{'i': 4, 'f': 4, 'h': 8, 'd': 8}[tag]
+		if len(b) < size {
+			return m, fmt.Errorf("invalid OSC message %s: truncated arguments", m.Address)
+		}
+		switch tag {
+		case 'i':
+			m.Args = append(m.Args, int32(binary.BigEndian.Uint32(b)))
+		case 'f':
+			m.Args = append(m.Args, math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case 'h':
+			m.Args = append(m.Args, int64(binary.BigEndian.Uint64(b)))
+		case 'd':
+			m.Args = append(m.Args, math.Float64frombits(binary.BigEndian.Uint64(b)))
+		case 's':
+			var s string
+			if s, b, err = readString(b); err != nil {
+				return m, fmt.Errorf("invalid OSC message %s: %w", m.Address, err)
+			}
+			m.Args = append(m.Args, s)
+		case 'b':
+			if len(b) < 4 {
+				return m, fmt.Errorf("invalid OSC message %s: truncated blob", m.Address)
+			}
+			n := int(binary.BigEndian.Uint32(b))
+			if n < 0 || 4+n > len(b) {
+				return m, fmt.Errorf("invalid OSC message %s: truncated blob", m.Address)
+			}
+			m.Args = append(m.Args, bytes.Clone(b[4:4+n]))
+			b = b[min(len(b), (4+n+3)/4*4):]
+		case 'T', 'F':
+			m.Args = append(m.Args, tag == 'T')
+		default:
+			return m, fmt.Errorf("invalid OSC message %s: unsupported type tag %q", m.Address, tag)
+		}
+		b = b[size:]
+	}
+	return m, nil
+}
+
+// Reads a null-terminated padded string, and returns the bytes after it.
+func readString(b []byte) (s string, rest []byte, err error) {
+	end := bytes.IndexByte(b, 0)
+	if end < 0 {
+		return "", nil, errors.New("unterminated string")
+	}
+	return string(b[:end]), b[min(len(b), (end+4)/4*4):], nil
+}
@@ -0,0 +1,118 @@
+package osc
+
+import (
+	"context"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Signal whose value is set from outside (ex: by OSC messages), it's safe for concurrent use.
+type Param struct {
+	bits atomic.Uint64
+}
+
+func NewParam(v float64) *Param {
+	p := &Param{}
+	p.Set(v)
+	return p
+}
+
+func (p *Param) Set(v float64)                  { p.bits.Store(math.Float64bits(v)) }
+func (p *Param) Value() float64                 { return math.Float64frombits(p.bits.Load()) }
+func (p *Param) At(x time.Duration) (y float64) { return p.Value() }
+
+// Dispatches incoming OSC messages to the handlers registered for their address.
+// Messages without a handler are ignored.
+type Server struct {
+	mu       sync.RWMutex
+	handlers map[string]func(Message)
+}
+
+func NewServer() *Server { return &Server{handlers: map[string]func(Message){}} }
+
+func (s *Server) Handle(address string, handle func(Message)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[address] = handle
+}
+
+// Sets the parameter to the first argument of the messages sent to the address.
+func (s *Server) Bind(address string, p *Param) {
+	s.Handle(address, func(m Message) {
+		if v, ok := m.Float(0); ok {
+			p.Set(v)
+		}
+	})
+}
+
+func (s *Server) Dispatch(m Message) {
+	s.mu.RLock()
+	handle := s.handlers[m.Address]
+	s.mu.RUnlock()
+	if handle != nil {
+		handle(m)
+	}
+}
+
+// Receives packets until ctx is done (invalid packets are ignored), conn is closed when it returns.
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Stops the goroutine closing conn when reading fails
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		messages, err := Decode(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, m := range messages {
+			s.Dispatch(m)
+		}
+	}
+}
+
+// Listens on the given UDP address (ex: ":9000") and receives packets until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, conn)
+}
+
+// Sends OSC messages to a given UDP address (ex: meter levels or triggers for a controller).
+type Client struct {
+	conn net.Conn
+}
+
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) Send(address string, args ...any) error {
+	b, err := Message{Address: address, Args: args}.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(b)
+	return err
+}
+
+func (c *Client) Close() error { return c.conn.Close() }
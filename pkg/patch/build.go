@@ -0,0 +1,352 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+// Instantiates the signal graph of the patch (stateful nodes run at the given sample rate).
+//
+// Node types and their parameters (signals unless noted otherwise, defaults in parentheses):
+//   - input: the signal processed by the patch (see BuildWithInput), silent otherwise
+//   - constant: value (number)
+//   - sine, square, saw, triangle: freq
+//   - pulse: freq, duty (0.5)
+//   - noise: seed (number)
+//   - lfo: shape (string: sine, square, saw or triangle), rate, depth, offset
+//   - samplehold: source, clock
+//   - slew: in, rise, fall (numbers, units per second)
+//   - adsr: attack, decay (durations), sustain (number), release, length (durations)
+//   - combine: inputs (list of signals)
+//   - amplify, ringmod: in, by
+//   - am: in, by, depth (1)
+//   - tremolo: in, speed, depth (numbers)
+//   - vibrato: in, speed, cents (numbers)
+//   - lowpass, highpass: in, cutoff, q (0.707)
+//   - bandpass, notch: in, center, q (0.707)
+//   - peaking: in, center, q (0.707), gain (dB)
+//   - lowshelf, highshelf: in, cutoff, q (0.707), gain (dB)
+//   - delay: in, time (duration), feedback, mix (numbers)
+//   - feedforwardcomb, feedbackcomb, allpass: in, delay (in seconds), max (duration, 1s), gain (number)
+//   - chorus, flanger: in, speed, depth, feedback, mix (numbers)
+//   - phaser: in, center, stages (number, 4), feedback, mix (numbers)
+//   - reverb: in, room, damping, mix (numbers)
+//   - distort: in, drive (number), curve (string: tanh, hardclip, cubic or foldback)
+//   - softclip: in, knee (number, 0.8)
+//   - compress: in, threshold, ratio (numbers), attack, release (durations)
+//   - duck: in, key, threshold, ratio (numbers), attack, release (durations)
+//   - gate: in, threshold (number), attack, hold, release (durations)
+//   - limit: in, ceiling (number), release (duration)
+//   - pluck: freq (number or note), decay (duration)
+//   - dcblock: in
+//
+// The patch must have a duration.
+func (p Patch) Build(rate int) (dsp.FiniteSignal, error) {
+	if p.Duration <= 0 {
+		return dsp.FiniteSignal{}, errors.New("missing duration")
+	}
+	return p.BuildWithInput(rate, dsp.Constant(0))
+}
+
+// Like Build, with the signal played by "input" nodes, to use the patch as an effect (ex: on a dsp.PCMReader).
+// The patch doesn't need a duration, since effects usually play as long as their input.
+func (p Patch) BuildWithInput(rate int, in dsp.Signal) (dsp.FiniteSignal, error) {
+	b := &builder{patch: p, rate: rate, input: in, built: map[string]dsp.Signal{}}
+	s, err := b.node(p.Output)
+	if err != nil {
+		return dsp.FiniteSignal{}, err
+	}
+	return dsp.F(time.Duration(p.Duration), s), nil
+}
+
+type builder struct {
+	patch    Patch
+	rate     int
+	input    dsp.Signal
+	built    map[string]dsp.Signal
+	building []string // Nodes being built (to detect loops)
+}
+
+func (b *builder) node(name string) (s dsp.Signal, err error) {
+	if s, ok := b.built[name]; ok {
+		return s, nil
+	}
+	n, ok := b.patch.Nodes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", name)
+	}
+	if slices.Contains(b.building, name) {
+		return nil, fmt.Errorf("loop between nodes: %s -> %s", strings.Join(b.building, " -> "), name)
+	}
+	b.building = append(b.building, name)
+	defer func() { b.building = b.building[:len(b.building)-1] }()
+
+	var typ string
+	if err := json.Unmarshal(n["type"], &typ); err != nil {
+		return nil, fmt.Errorf("node %q: missing or invalid type", name)
+	}
+	p := &params{builder: b, node: n}
+	s = p.build(typ)
+	if p.err != nil {
+		return nil, fmt.Errorf("node %q: %w", name, p.err)
+	}
+	if s == nil {
+		return nil, fmt.Errorf("node %q: unknown type %q", name, typ)
+	}
+	b.built[name] = s
+	return s, nil
+}
+
+// Reads the parameters of a node, keeping the first error.
+type params struct {
+	*builder
+	node Node
+	err  error
+}
+
+func (p *params) build(typ string) dsp.Signal {
+	rate := p.rate
+	switch typ {
+	case "input":
+		return p.input
+	case "constant":
+		return dsp.Constant(p.number("value", 0))
+	case "sine":
+		return dsp.Sine(p.signal("freq", nil))
+	case "square":
+		return dsp.Square(p.signal("freq", nil))
+	case "saw":
+		return dsp.Saw(p.signal("freq", nil))
+	case "triangle":
+		return dsp.Triangle(p.signal("freq", nil))
+	case "pulse":
+		return dsp.Pulse(p.signal("freq", nil), p.signal("duty", dsp.Constant(0.5)))
+	case "noise":
+		return dsp.Noise(uint64(p.number("seed", 0)))
+	case "lfo":
+		return dsp.LFO(p.oscillator("shape"), p.signal("rate", nil), p.signal("depth", nil), p.signal("offset", dsp.Constant(0)))
+	case "samplehold":
+		return dsp.SampleHold(p.signal("source", nil), p.signal("clock", nil), rate)
+	case "slew":
+		return dsp.Slew(p.signal("in", nil), rate, p.number("rise", 1000), p.number("fall", 1000))
+	case "adsr":
+		env := dsp.ADSR{
+			Attack:  p.duration("attack", 0),
+			Decay:   p.duration("decay", 0),
+			Sustain: p.number("sustain", 1),
+			Release: p.duration("release", 0),
+		}
+		return env.Note(p.duration("length", time.Duration(p.patch.Duration)))
+	case "combine":
+		return dsp.Combine(p.signals("inputs")...)
+	case "amplify":
+		return dsp.Amplify(p.signal("in", nil), p.signal("by", nil))
+	case "ringmod":
+		return dsp.RingMod(p.signal("in", nil), p.signal("by", nil))
+	case "am":
+		return dsp.AM(p.signal("in", nil), p.signal("by", nil), p.signal("depth", dsp.Constant(1)))
+	case "tremolo":
+		return dsp.Tremolo(p.signal("in", nil), p.number("speed", 6), p.number("depth", 0.5))
+	case "vibrato":
+		return dsp.Vibrato(p.signal("in", nil), p.number("speed", 6), p.number("cents", 20))
+	case "lowpass":
+		return dsp.LowPass(p.signal("in", nil), rate, p.signal("cutoff", nil), p.signal("q", dsp.Constant(0.707)))
+	case "highpass":
+		return dsp.HighPass(p.signal("in", nil), rate, p.signal("cutoff", nil), p.signal("q", dsp.Constant(0.707)))
+	case "bandpass":
+		return dsp.BandPass(p.signal("in", nil), rate, p.signal("center", nil), p.signal("q", dsp.Constant(0.707)))
+	case "notch":
+		return dsp.Notch(p.signal("in", nil), rate, p.signal("center", nil), p.signal("q", dsp.Constant(0.707)))
+	case "peaking":
+		return dsp.Peaking(p.signal("in", nil), rate, p.signal("center", nil), p.signal("q", dsp.Constant(0.707)), p.signal("gain", nil))
+	case "lowshelf":
+		return dsp.LowShelf(p.signal("in", nil), rate, p.signal("cutoff", nil), p.signal("q", dsp.Constant(0.707)), p.signal("gain", nil))
+	case "highshelf":
+		return dsp.HighShelf(p.signal("in", nil), rate, p.signal("cutoff", nil), p.signal("q", dsp.Constant(0.707)), p.signal("gain", nil))
+	case "delay":
+		return dsp.Delay(p.signal("in", nil), rate, p.duration("time", 0), p.number("feedback", 0), p.number("mix", 0.5))
+	case "feedforwardcomb":
+		return dsp.FeedforwardComb(p.signal("in", nil), rate, p.signal("delay", nil), p.duration("max", time.Second), p.number("gain", 0.5))
+	case "feedbackcomb":
+		return dsp.FeedbackComb(p.signal("in", nil), rate, p.signal("delay", nil), p.duration("max", time.Second), p.number("gain", 0.5))
+	case "allpass":
+		return dsp.Allpass(p.signal("in", nil), rate, p.signal("delay", nil), p.duration("max", time.Second), p.number("gain", 0.5))
+	case "chorus":
+		return dsp.Chorus(p.signal("in", nil), rate, p.number("speed", 0.5), p.number("depth", 0.5), p.number("feedback", 0), p.number("mix", 0.5))
+	case "flanger":
+		return dsp.Flanger(p.signal("in", nil), rate, p.number("speed", 0.2), p.number("depth", 0.5), p.number("feedback", 0.5), p.number("mix", 0.5))
+	case "phaser":
+		return dsp.Phaser(p.signal("in", nil), rate, p.signal("center", nil), int(p.number("stages", 4)), p.number("feedback", 0.5), p.number("mix", 0.5))
+	case "reverb":
+		return dsp.Reverb(p.signal("in", nil), rate, p.number("room", 0.5), p.number("damping", 0.5), p.number("mix", 0.3))
+	case "distort":
+		return dsp.Distort(p.signal("in", nil), p.number("drive", 1), p.curve("curve"))
+	case "softclip":
+		return dsp.SoftClip(p.signal("in", nil), p.number("knee", 0.8))
+	case "compress":
+		return dsp.Compress(p.signal("in", nil), rate, p.number("threshold", -12), p.number("ratio", 4),
+			p.duration("attack", 10*time.Millisecond), p.duration("release", 100*time.Millisecond))
+	case "duck":
+		return dsp.Duck(p.signal("in", nil), p.signal("key", nil), rate, p.number("threshold", -24), p.number("ratio", 8),
+			p.duration("attack", time.Millisecond), p.duration("release", 200*time.Millisecond))
+	case "gate":
+		return dsp.Gate(p.signal("in", nil), rate, p.number("threshold", -40),
+			p.duration("attack", time.Millisecond), p.duration("hold", 20*time.Millisecond), p.duration("release", 100*time.Millisecond))
+	case "limit":
+		return dsp.Limit(p.signal("in", nil), rate, p.number("ceiling", -1), p.duration("release", 50*time.Millisecond))
+	case "pluck":
+		return dsp.Pluck(p.frequency("freq"), rate, p.duration("decay", time.Second))
+	case "dcblock":
+		return dsp.DCBlock(p.signal("in", nil), rate)
+	}
+	return nil
+}
+
+func (p *params) fail(key string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%s: %w", key, err)
+	}
+}
+
+// Returns the parameter as a signal (or def if it's missing, def nil means it's required).
+func (p *params) signal(key string, def dsp.Signal) dsp.Signal {
+	raw, ok := p.node[key]
+	if !ok {
+		if def == nil {
+			p.fail(key, errors.New("missing parameter"))
+			return dsp.Constant(0)
+		}
+		return def
+	}
+	return p.value(key, raw)
+}
+
+func (p *params) signals(key string) (signals []dsp.Signal) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(p.node[key], &raws); err != nil {
+		p.fail(key, errors.New("expected a list of signals"))
+		return nil
+	}
+	for _, raw := range raws {
+		signals = append(signals, p.value(key, raw))
+	}
+	return signals
+}
+
+// Decodes a number (constant), a node name or a note name.
+func (p *params) value(key string, raw json.RawMessage) dsp.Signal {
+	var v float64
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return dsp.Constant(v)
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		p.fail(key, fmt.Errorf("expected a number, a node or a note, got %s", raw))
+		return dsp.Constant(0)
+	}
+	if _, ok := p.patch.Nodes[name]; ok {
+		s, err := p.builder.node(name)
+		if err != nil {
+			p.fail(key, err)
+			return dsp.Constant(0)
+		}
+		return s
+	}
+	n, err := music.Parse(name)
+	if err != nil {
+		p.fail(key, fmt.Errorf("%q is neither a node nor a note", name))
+		return dsp.Constant(0)
+	}
+	return n
+}
+
+// Returns a frequency given as a number or a note name.
+func (p *params) frequency(key string) float64 {
+	var v float64
+	if err := json.Unmarshal(p.node[key], &v); err == nil {
+		return v
+	}
+	var name string
+	json.Unmarshal(p.node[key], &name)
+	n, err := music.Parse(name)
+	if err != nil {
+		p.fail(key, errors.New("expected a frequency or a note"))
+		return 0
+	}
+	return n.Hz()
+}
+
+func (p *params) number(key string, def float64) float64 {
+	raw, ok := p.node[key]
+	if !ok {
+		return def
+	}
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		p.fail(key, fmt.Errorf("expected a number, got %s", raw))
+	}
+	return v
+}
+
+func (p *params) duration(key string, def time.Duration) time.Duration {
+	raw, ok := p.node[key]
+	if !ok {
+		return def
+	}
+	var d Duration
+	if err := json.Unmarshal(raw, &d); err != nil {
+		p.fail(key, err)
+	}
+	return time.Duration(d)
+}
+
+func (p *params) str(key string, def string) string {
+	raw, ok := p.node[key]
+	if !ok {
+		return def
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		p.fail(key, fmt.Errorf("expected a string, got %s", raw))
+	}
+	return s
+}
+
+func (p *params) oscillator(key string) dsp.Oscillator {
+	switch name := p.str(key, "sine"); name {
+	case "sine":
+		return dsp.Sine
+	case "square":
+		return dsp.Square
+	case "saw":
+		return dsp.Saw
+	case "triangle":
+		return dsp.Triangle
+	default:
+		p.fail(key, fmt.Errorf("unknown oscillator %q", name))
+		return dsp.Sine
+	}
+}
+
+func (p *params) curve(key string) dsp.Curve {
+	switch name := p.str(key, "tanh"); name {
+	case "tanh":
+		return dsp.Tanh
+	case "hardclip":
+		return dsp.HardClip
+	case "cubic":
+		return dsp.Cubic
+	case "foldback":
+		return dsp.Foldback
+	default:
+		p.fail(key, fmt.Errorf("unknown curve %q", name))
+		return dsp.Tanh
+	}
+}
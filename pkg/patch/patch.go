@@ -0,0 +1,78 @@
+// Package patch loads signal graphs described in JSON, so patches can be shared without recompiling Go code.
+//
+// A patch is a set of named nodes, each with a type (oscillator, envelope, effect, etc.) and parameters.
+// Parameters taking a signal can be a number (a constant), a note name ("A4", for its frequency)
+// or the name of another node. Durations are strings (ex: "250ms") or numbers (in seconds).
+//
+//	{
+//		"duration": "4s",
+//		"output": "out",
+//		"nodes": {
+//			"lfo": {"type": "lfo", "shape": "sine", "rate": 0.5, "depth": 800, "offset": 1200},
+//			"osc": {"type": "saw", "freq": "A2"},
+//			"filter": {"type": "lowpass", "in": "osc", "cutoff": "lfo", "q": 2},
+//			"env": {"type": "adsr", "attack": "10ms", "decay": "200ms", "sustain": 0.6, "release": "1s", "length": "2s"},
+//			"out": {"type": "amplify", "in": "filter", "by": "env"}
+//		}
+//	}
+//
+// See Build for the list of node types and their parameters.
+// Patches are JSON only: YAML would require a dependency outside of the standard library.
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type Patch struct {
+	Duration Duration        `json:"duration"` // Required by Build
+	Output   string          `json:"output"`   // Name of the node played, defaults to "out"
+	Nodes    map[string]Node `json:"nodes"`
+}
+
+// Parameters of a node (including its "type").
+type Node map[string]json.RawMessage
+
+// Duration that can be decoded from a string (ex: "1m30s") or a number of seconds.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(b, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid duration %s: expected a string or a number of seconds", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(time.Duration(d).String()) }
+
+func Parse(b []byte) (p Patch, err error) {
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("parse patch: %w", err)
+	}
+	if p.Output == "" {
+		p.Output = "out"
+	}
+	return p, nil
+}
+
+func Load(path string) (p Patch, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	return Parse(b)
+}
@@ -0,0 +1,55 @@
+package patch
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// How often Watch checks whether the patch file changed.
+const WatchInterval = 250 * time.Millisecond
+
+// Loads the patch file into the hot swap signal, then reloads it whenever it's modified, until ctx is done.
+// The new signal graph is crossfaded in (see dsp.HotSwap), so playback never drops out.
+// onReload is called after each (re)load, with a non-nil error when the patch couldn't be loaded
+// (the previous signal then keeps playing, so mistakes can be fixed live).
+func Watch(ctx context.Context, path string, rate int, h *dsp.HotSwap, onReload func(err error)) {
+	var modified time.Time
+	var size int64
+	missing := false
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			if !missing {
+				onReload(err) // Only reported once (editors may delete the file before writing it again)
+			}
+			missing = true
+		case missing || !info.ModTime().Equal(modified) || info.Size() != size:
+			missing, modified, size = false, info.ModTime(), info.Size()
+			onReload(reload(path, rate, h))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func reload(path string, rate int, h *dsp.HotSwap) error {
+	p, err := Load(path)
+	if err != nil {
+		return err
+	}
+	s, err := p.BuildWithInput(rate, dsp.Constant(0)) // Live patches play until stopped, they don't need a duration
+	if err != nil {
+		return err
+	}
+	h.Swap(s.Signal)
+	return nil
+}
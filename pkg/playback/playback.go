@@ -0,0 +1,168 @@
+// Package playback plays signals on the system audio device in real time.
+//
+// Frames are rendered ahead of time in small chunks and streamed (as raw 16-bit PCM)
+// to an audio player process (ffplay, aplay or sox) which talks to the audio device.
+package playback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+var ErrNoPlayer = errors.New("no audio player found (install ffplay, aplay or sox)")
+
+type Options struct {
+	Rate       int                      // Sample rate (in Hertz), defaults to 44100
+	Buffer     time.Duration            // How much audio is rendered ahead of playback, defaults to 200ms
+	OnUnderrun func(at time.Duration)   // Called when rendering falls behind playback (silence is played instead)
+	Command    func(rate int) *exec.Cmd // Player command reading s16be mono PCM on stdin, defaults to the first available player
+}
+
+// Number of chunks the render-ahead buffer is divided into.
+const chunksPerBuffer = 4
+
+// Plays the signal from 0 to d and returns once playback is over (or ctx is done).
+func Play(ctx context.Context, s dsp.Signal, d time.Duration, opts Options) error {
+	if opts.Rate == 0 {
+		opts.Rate = 44100
+	}
+	if opts.Buffer == 0 {
+		opts.Buffer = 200 * time.Millisecond
+	}
+	if opts.Command == nil {
+		opts.Command = DefaultCommand
+	}
+	cmd := opts.Command(opts.Rate)
+	if cmd == nil {
+		return ErrNoPlayer
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start audio player: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		cmd.Process.Kill()
+	}()
+
+	chunkSize := int(opts.Buffer.Seconds() * float64(opts.Rate) / chunksPerBuffer)
+	chunkSize = max(chunkSize, 1)
+	chunkDuration := time.Duration(chunkSize) * time.Second / time.Duration(opts.Rate)
+	chunks := make(chan []byte, chunksPerBuffer)
+	go render(ctx, chunks, s, d, opts.Rate, chunkSize)
+
+	err = write(ctx, stdin, chunks, chunkSize, chunkDuration, opts.OnUnderrun)
+	stdin.Close()
+	if err != nil {
+		cancel()
+		cmd.Wait()
+		return err
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func render(ctx context.Context, chunks chan<- []byte, s dsp.Signal, d time.Duration, rate, chunkSize int) {
+	defer close(chunks)
+	total := dsp.FrameCount(d, rate)
+	frames := make([]float64, 0, chunkSize)
+	e := dsp.NewPCMEncoder(rate, dsp.EncodeOptions{Format: dsp.Int16})
+	for i := range total {
+		frames = append(frames, s.At(dsp.FrameTime(i, rate)))
+		if len(frames) == chunkSize || i == total-1 {
+			select {
+			case chunks <- e.Encode(frames):
+			case <-ctx.Done():
+				return
+			}
+			frames = frames[:0]
+		}
+	}
+}
+
+// Writes rendered chunks to the player.
+// If no chunk is ready by the time the player should have played everything written so far,
+// a chunk of silence is written so the audio device doesn't run dry.
+func write(
+	ctx context.Context,
+	w io.Writer,
+	chunks <-chan []byte,
+	chunkSize int,
+	chunkDuration time.Duration,
+	onUnderrun func(at time.Duration),
+) error {
+	silence := make([]byte, 2*chunkSize) // Int16 zeros
+	var start time.Time
+	var written, rendered time.Duration
+	for {
+		var deadline <-chan time.Time
+		var timer *time.Timer
+		if !start.IsZero() {
+			timer = time.NewTimer(time.Until(start.Add(written)))
+			deadline = timer.C
+		}
+
+		var chunk []byte
+		select {
+		case c, ok := <-chunks:
+			if !ok {
+				return nil
+			}
+			chunk = c
+			rendered += chunkDuration
+		case <-deadline:
+			chunk = silence
+			if onUnderrun != nil {
+				onUnderrun(rendered)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return fmt.Errorf("write to audio player: %w", err)
+		}
+		written += chunkDuration
+	}
+}
+
+// Returns a command for the first audio player found in $PATH (or nil if there is none).
+func DefaultCommand(rate int) *exec.Cmd {
+	r := strconv.Itoa(rate)
+	switch {
+	case lookPath("ffplay"):
+		return exec.Command("ffplay", "-loglevel", "error", "-nodisp", "-autoexit", "-f", "s16be", "-ar", r, "-i", "-")
+	case lookPath("aplay"):
+		return exec.Command("aplay", "-q", "-t", "raw", "-f", "S16_BE", "-c", "1", "-r", r, "-")
+	case lookPath("play"):
+		return exec.Command("play", "-q", "-t", "raw", "-e", "signed-integer", "-b", "16", "-B", "-c", "1", "-r", r, "-")
+	}
+	return nil
+}
+
+func lookPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,18 @@
+// Package webaudio runs signals in the browser (compiled to WebAssembly), through the Web Audio API.
+//
+// The Go program renders frames on the main thread (see Serve), and an AudioWorklet
+// (static/worklet.js) plays them, asking for more frames before running out.
+//
+//	//go:build js && wasm
+//	func main() { webaudio.Serve("gomusic", song, 44100) }
+//
+// Build it with "GOOS=js GOARCH=wasm go build -o main.wasm", and serve main.wasm along with the files in
+// static (see Assets) and "wasm_exec.js" (from "$(go env GOROOT)/lib/wasm") over HTTP.
+package webaudio
+
+import "embed"
+
+// Web page, glue code and AudioWorklet processor playing the frames rendered by Serve.
+//
+//go:embed static
+var Assets embed.FS
@@ -0,0 +1,17 @@
+// Starts the Go program (main.wasm) and plays the signal it serves (see webaudio.Serve).
+async function start(name = "gomusic") {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch("main.wasm"), go.importObject);
+  go.run(instance);
+  const source = globalThis[name];
+
+  const ctx = new AudioContext({ sampleRate: source.rate });
+  await ctx.audioWorklet.addModule("worklet.js");
+  const node = new AudioWorkletNode(ctx, "gomusic", { outputChannelCount: [1] });
+  node.port.onmessage = (e) => {
+    const frames = source.render(e.data);
+    node.port.postMessage(frames, [frames.buffer]);
+  };
+  node.connect(ctx.destination);
+  return ctx;
+}
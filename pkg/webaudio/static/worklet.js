@@ -0,0 +1,44 @@
+// Plays the frames sent by the main thread (see main.js), asking for more before running out.
+class GoMusicProcessor extends AudioWorkletProcessor {
+  constructor() {
+    super();
+    this.queue = [];    // Float32Arrays waiting to be played
+    this.offset = 0;    // Position in the first array of the queue
+    this.queued = 0;    // Number of frames in the queue (not played yet)
+    this.requested = 0; // Number of frames asked for (not received yet)
+    this.port.onmessage = (e) => {
+      this.queue.push(e.data);
+      this.queued += e.data.length;
+      this.requested -= e.data.length;
+    };
+  }
+
+  process(inputs, outputs) {
+    const out = outputs[0][0];
+    let i = 0;
+    while (i < out.length && this.queue.length > 0) {
+      const chunk = this.queue[0];
+      const n = Math.min(out.length - i, chunk.length - this.offset);
+      out.set(chunk.subarray(this.offset, this.offset + n), i);
+      i += n;
+      this.offset += n;
+      this.queued -= n;
+      if (this.offset === chunk.length) {
+        this.queue.shift();
+        this.offset = 0;
+      }
+    }
+    out.fill(0, i); // Underrun: silence
+
+    // Keep about 100ms of audio ahead.
+    const ahead = Math.round(sampleRate / 10);
+    if (this.queued + this.requested < ahead) {
+      const n = ahead - this.queued - this.requested;
+      this.requested += n;
+      this.port.postMessage(n);
+    }
+    return true;
+  }
+}
+
+registerProcessor("gomusic", GoMusicProcessor);
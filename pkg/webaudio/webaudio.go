@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+package webaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"syscall/js"
+
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+)
+
+// Exposes the signal to JavaScript as globalThis[name], and blocks forever:
+//   - render(n) returns the next n frames (as a Float32Array) at the given sample rate
+//   - seek(frame) moves to the given frame
+//   - rate is the sample rate
+func Serve(name string, s dsp.Signal, rate int) {
+	var frame int64
+	render := js.FuncOf(func(this js.Value, args []js.Value) any {
+		n := args[0].Int()
+		b := make([]byte, 4*n)
+		for i := range n {
+			v := float32(s.At(dsp.FrameTime(frame, rate)))
+			binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+			frame++
+		}
+		out := js.Global().Get("Float32Array").New(n)
+		js.CopyBytesToJS(js.Global().Get("Uint8Array").New(out.Get("buffer")), b)
+		return out
+	})
+	seek := js.FuncOf(func(this js.Value, args []js.Value) any {
+		frame = int64(args[0].Int())
+		return nil
+	})
+	js.Global().Set(name, js.ValueOf(map[string]any{"render": render, "seek": seek, "rate": rate}))
+	select {}
+}
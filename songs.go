@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/ejuju/poc-go-music/pkg/dsp"
+	"github.com/ejuju/poc-go-music/pkg/music"
+)
+
+// Compositions available from the command line (by name).
+var songs = map[string]func() dsp.FiniteSignal{
+	"demo": demo,
+}
+
+// Four chords fading in and out.
+func demo() dsp.FiniteSignal {
+	bpm := music.BPM(127)
+
+	chord1 := music.Chord{Root: music.C5, Quality: music.MajorTriad}.Play(dsp.Sine)
+	chord2 := music.Chord{Root: music.A4, Quality: music.MinorTriad}.Play(dsp.Sine)
+	chord3 := music.Chord{Root: music.E4, Quality: music.MinorTriad, Inversion: 2}.Play(dsp.Sine)
+	chord4 := music.Chord{Root: music.D4, Quality: music.MajorTriad, Inversion: 2}.Play(dsp.Sine)
+
+	s := dsp.Sequence(
+		dsp.F(bpm.T(4), dsp.Amplify(chord1, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
+		dsp.F(bpm.T(4), dsp.Amplify(chord2, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
+		dsp.F(bpm.T(4), dsp.Amplify(chord3, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
+		dsp.F(bpm.T(4), dsp.Amplify(chord4, dsp.Sequence(dsp.Lerp(0, 1, bpm.T(2)), dsp.Lerp(1, 0, bpm.T(2))))),
+	)
+	return dsp.F(bpm.T(16), s)
+}